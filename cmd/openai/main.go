@@ -0,0 +1,113 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+// Command openai is a small command line companion to the openai package.
+//
+// Usage:
+//
+//	openai prep [flags] train.jsonl
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/AGMETEOR/openai-go/openai/finetune/prep"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `usage: openai <command> [arguments]
+
+commands:
+  prep    validate a JSONL fine-tuning dataset before uploading it
+`)
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "prep":
+		os.Exit(runPrep(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+func runPrep(args []string) int {
+	fs := flag.NewFlagSet("prep", flag.ExitOnError)
+	model := fs.String("model", prep.DefaultModel, "model to be fine-tuned, for token limits and pricing")
+	maxTokens := fs.Int("max-tokens", 0, "largest example allowed, in tokens (default: the model's fine-tuning limit)")
+	epochs := fs.Int("epochs", 3, "number of epochs to estimate the cost for")
+	out := fs.String("o", "", "write the cleaned dataset to `file`")
+	keepDups := fs.Bool("keep-duplicates", false, "keep duplicate examples in the cleaned dataset")
+	quiet := fs.Bool("q", false, "only print the summary, not every issue")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: openai prep [flags] file.jsonl\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer f.Close()
+
+	rep, err := prep.Analyze(f, prep.Options{
+		Model:               *model,
+		MaxTokensPerExample: *maxTokens,
+		Epochs:              *epochs,
+		KeepDuplicates:      *keepDups,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+
+	if !*quiet {
+		for _, issue := range rep.Issues {
+			fmt.Printf("%s: %v\n", fs.Arg(0), issue)
+		}
+	}
+	fmt.Printf("format:             %v\n", rep.Format)
+	fmt.Printf("examples:           %d lines, %d valid, %d duplicates, %d too long\n", rep.Lines, rep.Valid, rep.Duplicates, rep.TooLong)
+	fmt.Printf("tokens per example: min %d, median %d, max %d\n", rep.MinTokens, rep.MedianTokens, rep.MaxTokens)
+	fmt.Printf("training tokens:    %d per epoch, %d over %d epochs\n", rep.TrainingTokensPerEpoch, rep.TrainingTokensPerEpoch*rep.Epochs, rep.Epochs)
+	if rep.CostPerEpoch > 0 {
+		fmt.Printf("estimated cost:     $%.2f per epoch, $%.2f total for %s\n", rep.CostPerEpoch, rep.Cost, rep.Model)
+	} else {
+		fmt.Printf("estimated cost:     unknown for %s\n", rep.Model)
+	}
+
+	if *out != "" {
+		w, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err := rep.WriteCleaned(w); err != nil {
+			w.Close()
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err := w.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("wrote %d examples to %s\n", rep.Kept(), *out)
+		return 0
+	}
+
+	if rep.HasErrors() {
+		return 1
+	}
+	return 0
+}
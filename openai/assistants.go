@@ -0,0 +1,303 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+type AssistantsAPI Api
+
+// assistantsBeta is the header every Assistants API request must carry.
+var assistantsBeta = WithHeader("OpenAI-Beta", "assistants=v2")
+
+// Types of tools an assistant can use.
+const (
+	ToolTypeCodeInterpreter = "code_interpreter"
+	ToolTypeFileSearch      = "file_search"
+	ToolTypeFunction        = "function"
+)
+
+// AssistantTool is a tool enabled on an assistant or a run. Build one with
+// CodeInterpreterTool, FileSearchTool or FunctionTool.
+type AssistantTool struct {
+	Type       string                 `json:"type"`
+	FileSearch *FileSearchToolOptions `json:"file_search,omitempty"`
+	Function   *FunctionDefinition    `json:"function,omitempty"`
+}
+
+type FileSearchToolOptions struct {
+	// MaxNumResults is between 1 and 50.
+	MaxNumResults  int                       `json:"max_num_results,omitempty"`
+	RankingOptions *FileSearchRankingOptions `json:"ranking_options,omitempty"`
+}
+
+type FileSearchRankingOptions struct {
+	Ranker         string  `json:"ranker,omitempty"`
+	ScoreThreshold float64 `json:"score_threshold"`
+}
+
+// FunctionDefinition describes a function the model may call. Parameters is
+// a JSON Schema object.
+type FunctionDefinition struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Parameters  interface{} `json:"parameters,omitempty"`
+	Strict      *bool       `json:"strict,omitempty"`
+}
+
+// CodeInterpreterTool lets the assistant write and run Python code.
+func CodeInterpreterTool() AssistantTool {
+	return AssistantTool{Type: ToolTypeCodeInterpreter}
+}
+
+// FileSearchTool lets the assistant search the vector stores in its tool
+// resources. opts may be nil.
+func FileSearchTool(opts *FileSearchToolOptions) AssistantTool {
+	return AssistantTool{Type: ToolTypeFileSearch, FileSearch: opts}
+}
+
+// FunctionTool lets the assistant call fn, whose output the caller submits.
+func FunctionTool(fn FunctionDefinition) AssistantTool {
+	return AssistantTool{Type: ToolTypeFunction, Function: &fn}
+}
+
+// ToolResources are the files the code_interpreter and file_search tools use.
+type ToolResources struct {
+	CodeInterpreter *CodeInterpreterResources `json:"code_interpreter,omitempty"`
+	FileSearch      *FileSearchResources      `json:"file_search,omitempty"`
+}
+
+type CodeInterpreterResources struct {
+	FileIDs []string `json:"file_ids"`
+}
+
+type FileSearchResources struct {
+	VectorStoreIDs []string `json:"vector_store_ids"`
+}
+
+// Types of ResponseFormat.
+const (
+	ResponseFormatAuto       = "auto"
+	ResponseFormatText       = "text"
+	ResponseFormatJSONObject = "json_object"
+	ResponseFormatJSONSchema = "json_schema"
+)
+
+// ResponseFormat is the format the model must answer in. It is sent as the
+// string "auto" when Type is ResponseFormatAuto, and as an object otherwise.
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+// JSONSchema constrains the output of a ResponseFormatJSONSchema format.
+type JSONSchema struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Schema      interface{} `json:"schema,omitempty"`
+	Strict      *bool       `json:"strict,omitempty"`
+}
+
+func (f ResponseFormat) MarshalJSON() ([]byte, error) {
+	if f.Type == ResponseFormatAuto {
+		return json.Marshal(ResponseFormatAuto)
+	}
+	type plain ResponseFormat
+	return json.Marshal(plain(f))
+}
+
+func (f *ResponseFormat) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*f = ResponseFormat{Type: s}
+		return nil
+	}
+	type plain ResponseFormat
+	return json.Unmarshal(data, (*plain)(f))
+}
+
+// AssistantRequest creates an assistant. Model is required.
+type AssistantRequest struct {
+	Model          string            `json:"model"`
+	Name           string            `json:"name,omitempty"`
+	Description    string            `json:"description,omitempty"`
+	Instructions   string            `json:"instructions,omitempty"`
+	Tools          []AssistantTool   `json:"tools,omitempty"`
+	ToolResources  *ToolResources    `json:"tool_resources,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	Temperature    *float64          `json:"temperature,omitempty"`
+	TopP           *float64          `json:"top_p,omitempty"`
+	ResponseFormat *ResponseFormat   `json:"response_format,omitempty"`
+}
+
+// ModifyAssistantRequest modifies an assistant. Fields left nil are not
+// changed, so String("") clears a name, description or instructions.
+type ModifyAssistantRequest struct {
+	Model        *string `json:"model,omitempty"`
+	Name         *string `json:"name,omitempty"`
+	Description  *string `json:"description,omitempty"`
+	Instructions *string `json:"instructions,omitempty"`
+	// Tools replaces the tools of the assistant when not nil; an empty slice
+	// removes them all.
+	Tools         []AssistantTool `json:"tools"`
+	ToolResources *ToolResources  `json:"tool_resources,omitempty"`
+	// Metadata replaces the metadata of the assistant when not nil; an empty
+	// map removes it.
+	Metadata       map[string]string `json:"metadata"`
+	Temperature    *float64          `json:"temperature,omitempty"`
+	TopP           *float64          `json:"top_p,omitempty"`
+	ResponseFormat *ResponseFormat   `json:"response_format,omitempty"`
+}
+
+func (r ModifyAssistantRequest) MarshalJSON() ([]byte, error) {
+	type plain ModifyAssistantRequest
+	var tools *[]AssistantTool
+	if r.Tools != nil {
+		tools = &r.Tools
+	}
+	var metadata *map[string]string
+	if r.Metadata != nil {
+		metadata = &r.Metadata
+	}
+	return json.Marshal(struct {
+		plain
+		Tools    *[]AssistantTool   `json:"tools,omitempty"`
+		Metadata *map[string]string `json:"metadata,omitempty"`
+	}{plain(r), tools, metadata})
+}
+
+type Assistant struct {
+	ID             string            `json:"id"`
+	Object         string            `json:"object"`
+	CreatedAt      int64             `json:"created_at"`
+	Name           string            `json:"name"`
+	Description    string            `json:"description"`
+	Model          string            `json:"model"`
+	Instructions   string            `json:"instructions"`
+	Tools          []AssistantTool   `json:"tools"`
+	ToolResources  *ToolResources    `json:"tool_resources"`
+	Metadata       map[string]string `json:"metadata"`
+	Temperature    *float64          `json:"temperature"`
+	TopP           *float64          `json:"top_p"`
+	ResponseFormat *ResponseFormat   `json:"response_format"`
+}
+
+type AssistantList struct {
+	Object  string      `json:"object"`
+	Data    []Assistant `json:"data"`
+	FirstID string      `json:"first_id"`
+	LastID  string      `json:"last_id"`
+	HasMore bool        `json:"has_more"`
+}
+
+type AssistantDeleteResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
+
+// CreateAssistant creates an assistant with a model and instructions.
+func (a *AssistantsAPI) CreateAssistant(ctx context.Context, aReq *AssistantRequest) (*Assistant, *Response, error) {
+	u := "v1/assistants"
+	req, err := a.openAIClient.NewRequest(http.MethodPost, u, aReq, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	assistant := new(Assistant)
+
+	resp, err := a.openAIClient.Do(ctx, req, assistant)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return assistant, resp, nil
+}
+
+// RetrieveAssistant returns an assistant.
+func (a *AssistantsAPI) RetrieveAssistant(ctx context.Context, id string) (*Assistant, *Response, error) {
+	u := fmt.Sprintf("v1/assistants/%s", id)
+	req, err := a.openAIClient.NewRequest(http.MethodGet, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	assistant := new(Assistant)
+
+	resp, err := a.openAIClient.Do(ctx, req, assistant)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return assistant, resp, nil
+}
+
+// ModifyAssistant changes the fields of an assistant set in aReq.
+func (a *AssistantsAPI) ModifyAssistant(ctx context.Context, id string, aReq *ModifyAssistantRequest) (*Assistant, *Response, error) {
+	u := fmt.Sprintf("v1/assistants/%s", id)
+	req, err := a.openAIClient.NewRequest(http.MethodPost, u, aReq, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	assistant := new(Assistant)
+
+	resp, err := a.openAIClient.Do(ctx, req, assistant)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return assistant, resp, nil
+}
+
+// DeleteAssistant deletes an assistant.
+func (a *AssistantsAPI) DeleteAssistant(ctx context.Context, id string) (*AssistantDeleteResponse, *Response, error) {
+	u := fmt.Sprintf("v1/assistants/%s", id)
+	req, err := a.openAIClient.NewRequest(http.MethodDelete, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	adResp := new(AssistantDeleteResponse)
+
+	resp, err := a.openAIClient.Do(ctx, req, adResp)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return adResp, resp, nil
+}
+
+// List returns your assistants.
+func (a *AssistantsAPI) List(ctx context.Context, opts *ListOptions) (*AssistantList, *Response, error) {
+	u := opts.encode("v1/assistants")
+	req, err := a.openAIClient.NewRequest(http.MethodGet, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list := new(AssistantList)
+
+	resp, err := a.openAIClient.Do(ctx, req, list)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return list, resp, nil
+}
+
+// ListAll iterates over all of your assistants, fetching pages as needed.
+func (a *AssistantsAPI) ListAll(ctx context.Context, opts *ListOptions) iter.Seq2[Assistant, error] {
+	return paginate(ctx, opts, func(ctx context.Context, o *ListOptions) (page[Assistant], error) {
+		l, _, err := a.List(ctx, o)
+		if err != nil {
+			return page[Assistant]{}, err
+		}
+		return page[Assistant]{items: l.Data, hasMore: l.HasMore, lastID: l.LastID}, nil
+	}, func(asst Assistant) string { return asst.ID })
+}
@@ -0,0 +1,178 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// assistantsServer stores assistants in memory, answering like the API and
+// recording the body of every modification.
+func assistantsServer(t *testing.T) (*OpenAIClient, *[]string) {
+	assistants := make(map[string]*Assistant)
+	var order []string
+	var modifications []string
+	mux := http.NewServeMux()
+	beta := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("OpenAI-Beta"); got != "assistants=v2" {
+				writeAPIError(w, http.StatusBadRequest, "missing OpenAI-Beta header, got "+got)
+				return
+			}
+			h(w, r)
+		}
+	}
+	lookup := func(w http.ResponseWriter, r *http.Request) *Assistant {
+		a, ok := assistants[r.PathValue("id")]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "no assistant "+r.PathValue("id"))
+		}
+		return a
+	}
+	mux.HandleFunc("POST /v1/assistants", beta(func(w http.ResponseWriter, r *http.Request) {
+		a := new(Assistant)
+		if err := json.NewDecoder(r.Body).Decode(a); err != nil || a.Model == "" {
+			writeAPIError(w, http.StatusBadRequest, "model is required")
+			return
+		}
+		a.ID, a.Object = fmt.Sprintf("asst_%d", len(order)+1), "assistant"
+		assistants[a.ID] = a
+		order = append(order, a.ID)
+		json.NewEncoder(w).Encode(a)
+	}))
+	mux.HandleFunc("GET /v1/assistants/{id}", beta(func(w http.ResponseWriter, r *http.Request) {
+		if a := lookup(w, r); a != nil {
+			json.NewEncoder(w).Encode(a)
+		}
+	}))
+	mux.HandleFunc("POST /v1/assistants/{id}", beta(func(w http.ResponseWriter, r *http.Request) {
+		a := lookup(w, r)
+		if a == nil {
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		modifications = append(modifications, strings.TrimSpace(string(body)))
+		// Fields absent from the body are kept and metadata is replaced, as
+		// the API does.
+		var fields map[string]json.RawMessage
+		json.Unmarshal(body, &fields)
+		if _, ok := fields["metadata"]; ok {
+			a.Metadata = nil
+		}
+		json.Unmarshal(body, a)
+		json.NewEncoder(w).Encode(a)
+	}))
+	mux.HandleFunc("DELETE /v1/assistants/{id}", beta(func(w http.ResponseWriter, r *http.Request) {
+		if a := lookup(w, r); a != nil {
+			delete(assistants, a.ID)
+			json.NewEncoder(w).Encode(AssistantDeleteResponse{ID: a.ID, Object: "assistant.deleted", Deleted: true})
+		}
+	}))
+	mux.HandleFunc("GET /v1/assistants", beta(func(w http.ResponseWriter, r *http.Request) {
+		list := AssistantList{Object: "list"}
+		after := r.URL.Query().Get("after")
+		for _, id := range order {
+			a, ok := assistants[id]
+			switch {
+			case !ok:
+			case after != "":
+				if id == after {
+					after = ""
+				}
+			case len(list.Data) == 1:
+				list.HasMore = true
+			default:
+				list.Data = append(list.Data, *a)
+				list.LastID = id
+			}
+		}
+		json.NewEncoder(w).Encode(list)
+	}))
+	return newTestClient(t, mux), &modifications
+}
+
+func TestAssistants(t *testing.T) {
+	c, modifications := assistantsServer(t)
+	ctx := context.Background()
+
+	if _, _, err := c.Assistants.CreateAssistant(ctx, &AssistantRequest{Name: "no model"}); err == nil {
+		t.Error("CreateAssistant without a model succeeded")
+	}
+
+	created, _, err := c.Assistants.CreateAssistant(ctx, &AssistantRequest{
+		Model:        "gpt-4o",
+		Name:         "Helper",
+		Instructions: "Be brief.",
+		Tools:        []AssistantTool{CodeInterpreterTool(), FunctionTool(FunctionDefinition{Name: "lookup"})},
+		Metadata:     map[string]string{"team": "search"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || len(created.Tools) != 2 || created.Tools[1].Function.Name != "lookup" {
+		t.Fatalf("CreateAssistant() = %+v", created)
+	}
+	if _, _, err := c.Assistants.CreateAssistant(ctx, &AssistantRequest{Model: "gpt-4o-mini", Name: "Other"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _, err := c.Assistants.RetrieveAssistant(ctx, created.ID)
+	if err != nil || got.Name != "Helper" || got.Instructions != "Be brief." {
+		t.Fatalf("RetrieveAssistant() = %+v, %v", got, err)
+	}
+
+	var ids []string
+	for a, err := range c.Assistants.ListAll(ctx, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, a.ID)
+	}
+	if fmt.Sprint(ids) != "[asst_1 asst_2]" {
+		t.Errorf("ListAll() = %v, want both assistants", ids)
+	}
+
+	deleted, _, err := c.Assistants.DeleteAssistant(ctx, "asst_2")
+	if err != nil || !deleted.Deleted {
+		t.Fatalf("DeleteAssistant() = %+v, %v", deleted, err)
+	}
+	_, _, err = c.Assistants.RetrieveAssistant(ctx, "asst_2")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode() != http.StatusNotFound {
+		t.Errorf("RetrieveAssistant of a deleted assistant: err = %v, want a 404 *APIError", err)
+	}
+
+	// Only the fields set are sent; an empty name, tools and metadata clear them.
+	modified, _, err := c.Assistants.ModifyAssistant(ctx, created.ID, &ModifyAssistantRequest{Instructions: String("Be thorough.")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified.Name != "Helper" || modified.Instructions != "Be thorough." || len(modified.Tools) != 2 {
+		t.Errorf("after changing the instructions: %+v", modified)
+	}
+	modified, _, err = c.Assistants.ModifyAssistant(ctx, created.ID, &ModifyAssistantRequest{
+		Name:     String(""),
+		Tools:    []AssistantTool{},
+		Metadata: map[string]string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified.Name != "" || len(modified.Tools) != 0 || len(modified.Metadata) != 0 || modified.Instructions != "Be thorough." {
+		t.Errorf("after clearing the name, tools and metadata: %+v", modified)
+	}
+	want := []string{
+		`{"instructions":"Be thorough."}`,
+		`{"name":"","tools":[],"metadata":{}}`,
+	}
+	if fmt.Sprint(*modifications) != fmt.Sprint(want) {
+		t.Errorf("modifications sent:\n%s\nwant:\n%s", strings.Join(*modifications, "\n"), strings.Join(want, "\n"))
+	}
+}
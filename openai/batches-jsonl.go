@@ -0,0 +1,164 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// BatchInputLine is one request of a batch input file.
+type BatchInputLine struct {
+	CustomID string      `json:"custom_id"`
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Body     interface{} `json:"body"`
+}
+
+// BatchInputWriter writes a batch input file, one request per line. All
+// requests of a batch go to the same endpoint, and custom IDs must be unique.
+type BatchInputWriter struct {
+	w        *bufio.Writer
+	endpoint string
+	ids      map[string]bool
+	size     int64
+}
+
+// NewBatchInputWriter returns a BatchInputWriter writing to w. Call Flush
+// once all requests are added.
+func NewBatchInputWriter(w io.Writer) *BatchInputWriter {
+	return &BatchInputWriter{w: bufio.NewWriter(w), ids: make(map[string]bool)}
+}
+
+// AddChat adds a chat completion request.
+func (b *BatchInputWriter) AddChat(customID string, req *ChatRequest) error {
+	return b.Add(customID, EndpointChatCompletions, req)
+}
+
+// AddEmbedding adds an embeddings request.
+func (b *BatchInputWriter) AddEmbedding(customID string, req *EmbeddingRequest) error {
+	return b.Add(customID, EndpointEmbeddings, req)
+}
+
+// Add adds a request with body to endpoint, for endpoints without a typed helper.
+func (b *BatchInputWriter) Add(customID, endpoint string, body interface{}) error {
+	switch {
+	case customID == "":
+		return errors.New("openai: batch request needs a custom_id")
+	case b.ids[customID]:
+		return fmt.Errorf("openai: duplicate batch custom_id %q", customID)
+	case b.endpoint != "" && endpoint != b.endpoint:
+		return fmt.Errorf("openai: batch for %s cannot contain a request to %s", b.endpoint, endpoint)
+	case len(b.ids) >= MaxBatchRequests:
+		return fmt.Errorf("openai: a batch holds at most %d requests", MaxBatchRequests)
+	}
+
+	line, err := json.Marshal(BatchInputLine{CustomID: customID, Method: http.MethodPost, URL: endpoint, Body: body})
+	if err != nil {
+		return err
+	}
+	if b.size+int64(len(line))+1 > MaxBatchFileSize {
+		return fmt.Errorf("openai: a batch file holds at most %d bytes", MaxBatchFileSize)
+	}
+	if _, err := b.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	b.endpoint = endpoint
+	b.ids[customID] = true
+	b.size += int64(len(line)) + 1
+	return nil
+}
+
+// Endpoint returns the endpoint of the requests added so far.
+func (b *BatchInputWriter) Endpoint() string {
+	return b.endpoint
+}
+
+// Len returns the number of requests added.
+func (b *BatchInputWriter) Len() int {
+	return len(b.ids)
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (b *BatchInputWriter) Flush() error {
+	return b.w.Flush()
+}
+
+// BatchResult is the outcome of one request of a batch. Exactly one of
+// Response and Err is set.
+type BatchResult[T any] struct {
+	ID        string
+	CustomID  string
+	RequestID string
+	// StatusCode is the HTTP status the request was answered with, or zero
+	// if it failed before being sent.
+	StatusCode int
+	Response   *T
+	// Err is an *APIError describing why the request failed.
+	Err error
+}
+
+// batchOutputLine is one line of a batch output or error file.
+type batchOutputLine struct {
+	ID       string `json:"id"`
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		RequestID  string          `json:"request_id"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ParseBatchOutput reads a batch output or error file and returns the result
+// of every request by custom ID, with successful response bodies decoded into
+// T, such as ChatCompletion or EmbeddingResponse.
+func ParseBatchOutput[T any](r io.Reader) (map[string]BatchResult[T], error) {
+	results := make(map[string]BatchResult[T])
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		data := bytes.TrimSpace(sc.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var line batchOutputLine
+		if err := json.Unmarshal(data, &line); err != nil {
+			return nil, fmt.Errorf("openai: batch output line %d: %w", n, err)
+		}
+
+		res := BatchResult[T]{ID: line.ID, CustomID: line.CustomID}
+		switch resp := line.Response; {
+		case resp != nil && resp.StatusCode >= 200 && resp.StatusCode <= 299:
+			res.StatusCode, res.RequestID = resp.StatusCode, resp.RequestID
+			res.Response = new(T)
+			if err := json.Unmarshal(resp.Body, res.Response); err != nil {
+				return nil, fmt.Errorf("openai: batch output line %d: %w", n, err)
+			}
+		case resp != nil:
+			res.StatusCode, res.RequestID = resp.StatusCode, resp.RequestID
+			res.Err = CheckResponse(&http.Response{
+				StatusCode: resp.StatusCode,
+				Status:     http.StatusText(resp.StatusCode),
+				Body:       io.NopCloser(bytes.NewReader(resp.Body)),
+			})
+		case line.Error != nil:
+			res.Err = &APIError{Code: line.Error.Code, Message: line.Error.Message}
+		default:
+			res.Err = &APIError{Message: "batch request has neither a response nor an error"}
+		}
+		results[line.CustomID] = res
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,334 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AGMETEOR/openai-go/openai/internal/atomicfile"
+)
+
+// BatchFailedError is returned by RunBatch when a batch is rejected, usually
+// because its input file failed validation.
+type BatchFailedError struct {
+	ID     string
+	Errors []BatchError
+}
+
+func (e *BatchFailedError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("openai: batch %s failed", e.ID)
+	}
+	return fmt.Sprintf("openai: batch %s failed: %s", e.ID, e.Errors[0].Message)
+}
+
+// RunBatchOptions configures RunBatch. The zero value is usable.
+type RunBatchOptions struct {
+	// MaxRequestsPerBatch and MaxBytesPerBatch bound each batch input file.
+	// They default to MaxBatchRequests and MaxBatchFileSize.
+	MaxRequestsPerBatch int
+	MaxBytesPerBatch    int64
+	// PollInterval is the delay between status checks while batches make
+	// progress. Defaults to 30 seconds.
+	PollInterval time.Duration
+	// MaxPollInterval caps the delay, which doubles after every check without
+	// progress. Defaults to five minutes.
+	MaxPollInterval time.Duration
+	// StatePath, when set, is where the files and batches created are
+	// recorded. Calling RunBatch again with the same state file and requests
+	// picks up the batches already submitted instead of starting over. The
+	// file is removed once all results are collected.
+	StatePath string
+	// Metadata is attached to every batch created.
+	Metadata map[string]string
+	// OnStatus, when set, is called with every batch status fetched.
+	OnStatus func(Batch)
+}
+
+// batchRunState records the shards of a RunBatch call.
+type batchRunState struct {
+	// Fingerprint identifies the requests the state belongs to.
+	Fingerprint string          `json:"fingerprint"`
+	Shards      []batchRunShard `json:"shards"`
+}
+
+type batchRunShard struct {
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	InputFileID string `json:"input_file_id,omitempty"`
+	BatchID     string `json:"batch_id,omitempty"`
+}
+
+// RunBatch sends reqs through the Batch API and waits for them to complete.
+// The requests are split into as many batches as the input file limits
+// require. The results are returned in the order of reqs. Requests that
+// failed, or did not run before their batch expired or was cancelled, have
+// Err set; a batch rejected as a whole gives each of its requests a
+// *BatchFailedError. The error returned is only about RunBatch itself, such
+// as a failed upload or a cancelled ctx, so check the Err of every result.
+// The input files uploaded are deleted once the results are collected.
+func (b *BatchesAPI) RunBatch(ctx context.Context, reqs []ChatRequest, opts *RunBatchOptions) ([]BatchResult[ChatCompletion], error) {
+	if opts == nil {
+		opts = &RunBatchOptions{}
+	}
+	maxRequests := opts.MaxRequestsPerBatch
+	if maxRequests <= 0 || maxRequests > MaxBatchRequests {
+		maxRequests = MaxBatchRequests
+	}
+	maxBytes := opts.MaxBytesPerBatch
+	if maxBytes <= 0 || maxBytes > MaxBatchFileSize {
+		maxBytes = MaxBatchFileSize
+	}
+
+	// Encode every request up front: the shards and the fingerprint that ties
+	// a state file to these requests both depend on the exact lines.
+	lines := make([][]byte, len(reqs))
+	fp := sha256.New()
+	for i := range reqs {
+		line, err := json.Marshal(BatchInputLine{CustomID: batchCustomID(i), Method: http.MethodPost, URL: EndpointChatCompletions, Body: &reqs[i]})
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(line))+1 > maxBytes {
+			return nil, fmt.Errorf("openai: request %d is larger than a batch file", i)
+		}
+		lines[i] = append(line, '\n')
+		fp.Write(lines[i])
+	}
+	fingerprint := hex.EncodeToString(fp.Sum(nil))
+
+	state := loadBatchRunState(opts.StatePath)
+	if state == nil || state.Fingerprint != fingerprint {
+		state = &batchRunState{Fingerprint: fingerprint}
+		start, size := 0, int64(0)
+		for i, line := range lines {
+			if i > start && (i-start >= maxRequests || size+int64(len(line)) > maxBytes) {
+				state.Shards = append(state.Shards, batchRunShard{Start: start, End: i})
+				start, size = i, 0
+			}
+			size += int64(len(line))
+		}
+		if start < len(lines) {
+			state.Shards = append(state.Shards, batchRunShard{Start: start, End: len(lines)})
+		}
+	}
+
+	// Submit the shards not submitted yet.
+	for i := range state.Shards {
+		sh := &state.Shards[i]
+		if sh.BatchID == "" {
+			for j := sh.Start; j < sh.End; j++ {
+				if err := b.openAIClient.checkUsage(ctx, EndpointBatches, reqs[j].Model, reqs[j].User); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if sh.InputFileID == "" {
+			file, _, err := b.openAIClient.File.UploadFile(ctx, &FileUploadRequest{
+				File:    fmt.Sprintf("batch-%s-%d.jsonl", fingerprint[:12], i),
+				Reader:  bytes.NewReader(bytes.Join(lines[sh.Start:sh.End], nil)),
+				Purpose: FilePurposeBatch,
+			})
+			if err != nil {
+				return nil, err
+			}
+			sh.InputFileID = file.ID
+			if err := state.save(opts.StatePath); err != nil {
+				return nil, err
+			}
+		}
+		if sh.BatchID == "" {
+			batch, _, err := b.CreateBatch(ctx, &BatchRequest{
+				InputFileID: sh.InputFileID,
+				Endpoint:    EndpointChatCompletions,
+				Metadata:    opts.Metadata,
+			})
+			if err != nil {
+				return nil, err
+			}
+			sh.BatchID = batch.ID
+			if err := state.save(opts.StatePath); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	batches, err := b.waitForBatches(ctx, state.Shards, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the results and line them up with reqs.
+	results := make([]BatchResult[ChatCompletion], len(reqs))
+	for i, sh := range state.Shards {
+		batch := batches[i]
+		if batch.Status == BatchStatusFailed {
+			ferr := &BatchFailedError{ID: batch.ID}
+			if batch.Errors != nil {
+				ferr.Errors = batch.Errors.Data
+			}
+			for j := sh.Start; j < sh.End; j++ {
+				results[j] = BatchResult[ChatCompletion]{CustomID: batchCustomID(j), Err: ferr}
+			}
+			continue
+		}
+
+		for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+			if fileID == "" {
+				continue
+			}
+			var buf bytes.Buffer
+			if _, _, err := b.openAIClient.File.DownloadFileContent(ctx, fileID, &buf, nil); err != nil {
+				return nil, err
+			}
+			parsed, err := ParseBatchOutput[ChatCompletion](&buf)
+			if err != nil {
+				return nil, err
+			}
+			for id, res := range parsed {
+				j, ok := batchIndex(id)
+				if !ok || j < sh.Start || j >= sh.End {
+					continue
+				}
+				results[j] = res
+				if res.Response != nil {
+					b.openAIClient.recordUsage(ctx, EndpointBatches, firstNonEmpty(res.Response.Model, reqs[j].Model), reqs[j].User, res.Response.Usage)
+				}
+			}
+		}
+		for j := sh.Start; j < sh.End; j++ {
+			if results[j].Response == nil && results[j].Err == nil {
+				results[j] = BatchResult[ChatCompletion]{
+					CustomID: batchCustomID(j),
+					Err:      &APIError{Message: fmt.Sprintf("no result: batch %s is %s", batch.ID, batch.Status)},
+				}
+			}
+		}
+	}
+
+	// The results are in hand, so a file left behind is only clutter.
+	for _, sh := range state.Shards {
+		b.openAIClient.File.DeleteFile(ctx, sh.InputFileID)
+	}
+	if opts.StatePath != "" {
+		os.Remove(opts.StatePath)
+	}
+	return results, nil
+}
+
+// waitForBatches polls the batches of shards until they all reach a final
+// status and returns them in the same order.
+func (b *BatchesAPI) waitForBatches(ctx context.Context, shards []batchRunShard, opts *RunBatchOptions) ([]*Batch, error) {
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	maxInterval := opts.MaxPollInterval
+	if maxInterval < interval {
+		maxInterval = 5 * time.Minute
+		if maxInterval < interval {
+			maxInterval = interval
+		}
+	}
+
+	batches := make([]*Batch, len(shards))
+	progress := make([]int, len(shards))
+	delay := interval
+	for {
+		pending, moved := 0, false
+		for i, sh := range shards {
+			if batches[i] != nil && isTerminalBatchStatus(batches[i].Status) {
+				continue
+			}
+			batch, _, err := b.RetrieveBatch(ctx, sh.BatchID)
+			if err != nil {
+				if !isRetryable(err) {
+					return nil, err
+				}
+				pending++
+				continue
+			}
+			if opts.OnStatus != nil {
+				opts.OnStatus(*batch)
+			}
+			if n := batch.RequestCounts.Completed + batch.RequestCounts.Failed; n != progress[i] || batches[i] == nil || batch.Status != batches[i].Status {
+				progress[i] = n
+				moved = true
+			}
+			batches[i] = batch
+			if !isTerminalBatchStatus(batch.Status) {
+				pending++
+			}
+		}
+		if pending == 0 {
+			return batches, nil
+		}
+
+		if moved {
+			delay = interval
+		} else if delay *= 2; delay > maxInterval {
+			delay = maxInterval
+		}
+		if err := sleepCtx(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isTerminalBatchStatus reports whether a batch in status will not change anymore.
+func isTerminalBatchStatus(status string) bool {
+	switch status {
+	case BatchStatusCompleted, BatchStatusFailed, BatchStatusExpired, BatchStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// batchCustomID is the custom ID RunBatch gives request i.
+func batchCustomID(i int) string {
+	return "request-" + strconv.Itoa(i)
+}
+
+// batchIndex is the inverse of batchCustomID.
+func batchIndex(customID string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(customID, "request-"))
+	return n, err == nil && strings.HasPrefix(customID, "request-")
+}
+
+// loadBatchRunState reads the state at path, or returns nil if there is none.
+func loadBatchRunState(path string) *batchRunState {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	s := new(batchRunState)
+	if json.Unmarshal(data, s) != nil {
+		return nil
+	}
+	return s
+}
+
+// save writes the state to path. It does nothing when path is empty.
+func (s *batchRunState) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, data)
+}
@@ -0,0 +1,349 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchServer fakes the files and batches endpoints. A batch is in progress
+// on its first retrieval and done on the next. Its output file answers every
+// request with the content of its first message, in reverse order; requests
+// whose content is "bad" go to the error file, and a batch holding a request
+// for the model "reject" fails as a whole.
+type batchServer struct {
+	mu      sync.Mutex
+	files   map[string][]byte
+	batches map[string]*Batch
+	deleted []string
+	inputs  [][]string // custom IDs of each batch created
+}
+
+func newBatchServer(t *testing.T) (*batchServer, *OpenAIClient) {
+	s := &batchServer{files: make(map[string][]byte), batches: make(map[string]*Batch)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/files", func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		data, _ := io.ReadAll(f)
+		json.NewEncoder(w).Encode(File{ID: s.addFile(data)})
+	})
+	mux.HandleFunc("GET /v1/files/{id}/content", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		data, ok := s.files[r.PathValue("id")]
+		s.mu.Unlock()
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "no such file")
+			return
+		}
+		w.Write(data)
+	})
+	mux.HandleFunc("DELETE /v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.deleted = append(s.deleted, r.PathValue("id"))
+		s.mu.Unlock()
+		json.NewEncoder(w).Encode(FileDeleteResponse{ID: r.PathValue("id"), Deleted: true})
+	})
+	mux.HandleFunc("POST /v1/batches", func(w http.ResponseWriter, r *http.Request) {
+		var req BatchRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		batch := &Batch{ID: fmt.Sprintf("batch-%d", len(s.batches)+1), InputFileID: req.InputFileID, Status: BatchStatusValidating}
+		s.batches[batch.ID] = batch
+		var ids []string
+		for _, line := range s.inputLines(req.InputFileID) {
+			ids = append(ids, line.CustomID)
+		}
+		s.inputs = append(s.inputs, ids)
+		json.NewEncoder(w).Encode(batch)
+	})
+	mux.HandleFunc("GET /v1/batches/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		batch, ok := s.batches[r.PathValue("id")]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "no such batch")
+			return
+		}
+		if batch.Status == BatchStatusValidating {
+			batch.Status = BatchStatusInProgress
+		} else if batch.Status == BatchStatusInProgress {
+			s.finish(batch)
+		}
+		json.NewEncoder(w).Encode(batch)
+	})
+	return s, newTestClient(t, mux)
+}
+
+// addFile stores data as a new file and returns its ID. s.mu must not be held.
+func (s *batchServer) addFile(data []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addFileLocked(data)
+}
+
+func (s *batchServer) addFileLocked(data []byte) string {
+	id := fmt.Sprintf("file-%d", len(s.files)+1)
+	s.files[id] = data
+	return id
+}
+
+type batchInput struct {
+	CustomID string      `json:"custom_id"`
+	Body     ChatRequest `json:"body"`
+}
+
+func (s *batchServer) inputLines(fileID string) []batchInput {
+	var lines []batchInput
+	sc := bufio.NewScanner(bytes.NewReader(s.files[fileID]))
+	for sc.Scan() {
+		var line batchInput
+		json.Unmarshal(sc.Bytes(), &line)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// finish completes or fails batch, writing its output and error files.
+func (s *batchServer) finish(batch *Batch) {
+	lines := s.inputLines(batch.InputFileID)
+	var out, errs bytes.Buffer
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := lines[i]
+		if line.Body.Model == "reject" {
+			batch.Status = BatchStatusFailed
+			batch.Errors = &BatchErrors{Data: []BatchError{{Code: "invalid_model", Message: "model reject does not exist", Line: i + 1}}}
+			return
+		}
+		content := line.Body.Messages[0].Content
+		if content == "bad" {
+			fmt.Fprintf(&errs, `{"id":"r%d","custom_id":%q,"response":{"status_code":400,"body":{"error":{"message":"bad request"}}}}`+"\n", i, line.CustomID)
+			continue
+		}
+		body, _ := json.Marshal(ChatCompletion{Model: line.Body.Model, Choices: []Choice{{Message: Message{Role: "assistant", Content: content}}}})
+		fmt.Fprintf(&out, `{"id":"r%d","custom_id":%q,"response":{"status_code":200,"body":%s}}`+"\n", i, line.CustomID, body)
+	}
+	batch.Status = BatchStatusCompleted
+	batch.OutputFileID = s.addFileLocked(out.Bytes())
+	if errs.Len() > 0 {
+		batch.ErrorFileID = s.addFileLocked(errs.Bytes())
+	}
+}
+
+func batchChatRequests(contents ...string) []ChatRequest {
+	reqs := make([]ChatRequest, len(contents))
+	for i, c := range contents {
+		reqs[i] = ChatRequest{Model: "gpt-4o-mini", Messages: []Message{{Role: "user", Content: c}}}
+	}
+	return reqs
+}
+
+func fastBatchOptions() *RunBatchOptions {
+	return &RunBatchOptions{PollInterval: time.Millisecond, MaxPollInterval: time.Millisecond}
+}
+
+func TestRunBatchAlignsResults(t *testing.T) {
+	s, c := newBatchServer(t)
+	reqs := batchChatRequests("a", "bad", "c", "d", "e")
+	opts := fastBatchOptions()
+	opts.MaxRequestsPerBatch = 2
+
+	results, err := c.Batches.RunBatch(context.Background(), reqs, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(reqs) {
+		t.Fatalf("got %d results, want %d", len(results), len(reqs))
+	}
+	for i, res := range results {
+		if res.CustomID != batchCustomID(i) {
+			t.Errorf("results[%d].CustomID = %q", i, res.CustomID)
+		}
+		want := reqs[i].Messages[0].Content
+		if want == "bad" {
+			var apiErr *APIError
+			if !errors.As(res.Err, &apiErr) || apiErr.StatusCode() != http.StatusBadRequest {
+				t.Errorf("results[%d].Err = %v, want a 400 *APIError", i, res.Err)
+			}
+			continue
+		}
+		if res.Err != nil || res.Response == nil || res.Response.Choices[0].Message.Content != want {
+			t.Errorf("results[%d] = %+v, want content %q", i, res, want)
+		}
+	}
+
+	// Every input file is deleted, and only those.
+	var inputs []string
+	for _, b := range s.batches {
+		inputs = append(inputs, b.InputFileID)
+	}
+	sort.Strings(inputs)
+	sort.Strings(s.deleted)
+	if len(inputs) != 3 || fmt.Sprint(s.deleted) != fmt.Sprint(inputs) {
+		t.Errorf("deleted %v, want the input files %v", s.deleted, inputs)
+	}
+}
+
+func TestRunBatchShards(t *testing.T) {
+	line := func(i int, content string) int64 {
+		data, _ := json.Marshal(BatchInputLine{CustomID: batchCustomID(i), Method: http.MethodPost, URL: EndpointChatCompletions, Body: &batchChatRequests(content)[0]})
+		return int64(len(data)) + 1
+	}
+
+	tests := []struct {
+		name        string
+		reqs        []ChatRequest
+		maxRequests int
+		maxBytes    int64
+		want        string
+	}{
+		{
+			name:        "by count",
+			reqs:        batchChatRequests("a", "b", "c", "d", "e", "f", "g"),
+			maxRequests: 3,
+			want:        "[[request-0 request-1 request-2] [request-3 request-4 request-5] [request-6]]",
+		},
+		{
+			name:     "by bytes",
+			reqs:     batchChatRequests("a", "b", strings.Repeat("x", 100), "d"),
+			maxBytes: line(0, "a") + line(1, "b"),
+			want:     "[[request-0 request-1] [request-2] [request-3]]",
+		},
+		{
+			name:        "under both limits",
+			reqs:        batchChatRequests("a", "b"),
+			maxRequests: 10,
+			maxBytes:    1 << 20,
+			want:        "[[request-0 request-1]]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, c := newBatchServer(t)
+			opts := fastBatchOptions()
+			opts.MaxRequestsPerBatch, opts.MaxBytesPerBatch = tt.maxRequests, tt.maxBytes
+			if _, err := c.Batches.RunBatch(context.Background(), tt.reqs, opts); err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(s.inputs); got != tt.want {
+				t.Errorf("batches = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	_, c := newBatchServer(t)
+	opts := fastBatchOptions()
+	opts.MaxBytesPerBatch = 10
+	if _, err := c.Batches.RunBatch(context.Background(), batchChatRequests("a"), opts); err == nil {
+		t.Error("RunBatch with a request larger than a batch file succeeded")
+	}
+}
+
+func TestRunBatchFailedShard(t *testing.T) {
+	s, c := newBatchServer(t)
+	reqs := batchChatRequests("a", "b", "c", "d")
+	reqs[2].Model = "reject"
+	opts := fastBatchOptions()
+	opts.MaxRequestsPerBatch = 2
+
+	results, err := c.Batches.RunBatch(context.Background(), reqs, opts)
+	if err != nil {
+		t.Fatalf("RunBatch() error = %v, want the failure on the results only", err)
+	}
+	for i, res := range results[:2] {
+		if res.Err != nil || res.Response == nil {
+			t.Errorf("results[%d] = %+v, want a response", i, res)
+		}
+	}
+	for i, res := range results[2:] {
+		var ferr *BatchFailedError
+		if !errors.As(res.Err, &ferr) || ferr.ID != "batch-2" || len(ferr.Errors) != 1 {
+			t.Errorf("results[%d].Err = %v, want the *BatchFailedError of batch-2", i+2, res.Err)
+		}
+	}
+	if len(s.deleted) != 2 {
+		t.Errorf("deleted %v, want both input files", s.deleted)
+	}
+}
+
+func TestRunBatchResumes(t *testing.T) {
+	s, c := newBatchServer(t)
+	statePath := filepath.Join(t.TempDir(), "batch.json")
+	reqs := batchChatRequests("a", "b", "c")
+
+	// Stop waiting as soon as the batches are submitted.
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := fastBatchOptions()
+	opts.MaxRequestsPerBatch = 2
+	opts.StatePath = statePath
+	opts.OnStatus = func(Batch) { cancel() }
+	if _, err := c.Batches.RunBatch(ctx, reqs, opts); !errors.Is(err, context.Canceled) {
+		t.Fatalf("interrupted RunBatch() error = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(statePath); err != nil {
+		t.Fatalf("state file: %v", err)
+	}
+	if len(s.batches) != 2 || len(s.deleted) != 0 {
+		t.Fatalf("after the interruption: %d batches, deleted %v", len(s.batches), s.deleted)
+	}
+
+	opts.OnStatus = nil
+	results, err := c.Batches.RunBatch(context.Background(), reqs, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.batches) != 2 || len(s.files) != 2+2 {
+		t.Errorf("resumed RunBatch created %d batches and %d files, want to reuse 2 batches", len(s.batches), len(s.files))
+	}
+	for i, res := range results {
+		if res.Response == nil || res.Response.Choices[0].Message.Content != reqs[i].Messages[0].Content {
+			t.Errorf("results[%d] = %+v", i, res)
+		}
+	}
+	if _, err := os.Stat(statePath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("state file left behind: %v", err)
+	}
+}
+
+func TestRunBatchIgnoresStateOfOtherRequests(t *testing.T) {
+	s, c := newBatchServer(t)
+	statePath := filepath.Join(t.TempDir(), "batch.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := fastBatchOptions()
+	opts.StatePath = statePath
+	opts.OnStatus = func(Batch) { cancel() }
+	if _, err := c.Batches.RunBatch(ctx, batchChatRequests("a", "b"), opts); !errors.Is(err, context.Canceled) {
+		t.Fatalf("interrupted RunBatch() error = %v, want context.Canceled", err)
+	}
+
+	opts.OnStatus = nil
+	results, err := c.Batches.RunBatch(context.Background(), batchChatRequests("x", "y"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.batches) != 2 {
+		t.Errorf("got %d batches, want a new one for the other requests", len(s.batches))
+	}
+	if got := results[0].Response.Choices[0].Message.Content; got != "x" {
+		t.Errorf("results[0] content = %q, want %q", got, "x")
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+type BatchesAPI Api
+
+// Statuses of a batch.
+const (
+	BatchStatusValidating = "validating"
+	BatchStatusFailed     = "failed"
+	BatchStatusInProgress = "in_progress"
+	BatchStatusFinalizing = "finalizing"
+	BatchStatusCompleted  = "completed"
+	BatchStatusExpired    = "expired"
+	BatchStatusCancelling = "cancelling"
+	BatchStatusCancelled  = "cancelled"
+)
+
+// BatchCompletionWindow24h is the only completion window batches support.
+const BatchCompletionWindow24h = "24h"
+
+// Limits of a batch input file.
+const (
+	MaxBatchRequests = 50000
+	MaxBatchFileSize = 200 << 20
+)
+
+type BatchRequest struct {
+	// InputFileID is a JSONL file uploaded with purpose "batch", as written
+	// by a BatchInputWriter.
+	InputFileID string `json:"input_file_id"`
+	// Endpoint all requests of the batch are sent to, such as
+	// EndpointChatCompletions or EndpointEmbeddings.
+	Endpoint string `json:"endpoint"`
+	// CompletionWindow defaults to BatchCompletionWindow24h.
+	CompletionWindow string            `json:"completion_window"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+}
+
+type Batch struct {
+	ID               string             `json:"id"`
+	Object           string             `json:"object"`
+	Endpoint         string             `json:"endpoint"`
+	Errors           *BatchErrors       `json:"errors"`
+	InputFileID      string             `json:"input_file_id"`
+	CompletionWindow string             `json:"completion_window"`
+	Status           string             `json:"status"`
+	OutputFileID     string             `json:"output_file_id"`
+	ErrorFileID      string             `json:"error_file_id"`
+	CreatedAt        int64              `json:"created_at"`
+	InProgressAt     int64              `json:"in_progress_at"`
+	ExpiresAt        int64              `json:"expires_at"`
+	FinalizingAt     int64              `json:"finalizing_at"`
+	CompletedAt      int64              `json:"completed_at"`
+	FailedAt         int64              `json:"failed_at"`
+	ExpiredAt        int64              `json:"expired_at"`
+	CancellingAt     int64              `json:"cancelling_at"`
+	CancelledAt      int64              `json:"cancelled_at"`
+	RequestCounts    BatchRequestCounts `json:"request_counts"`
+	Metadata         map[string]string  `json:"metadata"`
+}
+
+type BatchErrors struct {
+	Object string       `json:"object"`
+	Data   []BatchError `json:"data"`
+}
+
+// BatchError is a problem with the batch's input file.
+type BatchError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Param   string `json:"param"`
+	// Line is the line of the input file the error is about, if any.
+	Line int `json:"line"`
+}
+
+type BatchRequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+type BatchList struct {
+	Object  string  `json:"object"`
+	Data    []Batch `json:"data"`
+	HasMore bool    `json:"has_more"`
+	FirstID string  `json:"first_id"`
+	LastID  string  `json:"last_id"`
+}
+
+// CreateBatch creates and starts a batch from an uploaded input file.
+func (b *BatchesAPI) CreateBatch(ctx context.Context, bReq *BatchRequest) (*Batch, *Response, error) {
+	if bReq.CompletionWindow == "" {
+		r := *bReq
+		r.CompletionWindow = BatchCompletionWindow24h
+		bReq = &r
+	}
+
+	u := "v1/batches"
+	req, err := b.openAIClient.NewRequest(http.MethodPost, u, bReq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	batch := new(Batch)
+
+	resp, err := b.openAIClient.Do(ctx, req, batch)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return batch, resp, nil
+}
+
+// RetrieveBatch returns a batch.
+func (b *BatchesAPI) RetrieveBatch(ctx context.Context, id string) (*Batch, *Response, error) {
+	u := fmt.Sprintf("v1/batches/%s", id)
+	req, err := b.openAIClient.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	batch := new(Batch)
+
+	resp, err := b.openAIClient.Do(ctx, req, batch)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return batch, resp, nil
+}
+
+// List returns your organization's batches.
+func (b *BatchesAPI) List(ctx context.Context, opts *ListOptions) (*BatchList, *Response, error) {
+	u := opts.encode("v1/batches")
+	req, err := b.openAIClient.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list := new(BatchList)
+
+	resp, err := b.openAIClient.Do(ctx, req, list)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return list, resp, nil
+}
+
+// ListAll iterates over all of your organization's batches, fetching pages as needed.
+func (b *BatchesAPI) ListAll(ctx context.Context, opts *ListOptions) iter.Seq2[Batch, error] {
+	return paginate(ctx, opts, func(ctx context.Context, o *ListOptions) (page[Batch], error) {
+		l, _, err := b.List(ctx, o)
+		if err != nil {
+			return page[Batch]{}, err
+		}
+		return page[Batch]{items: l.Data, hasMore: l.HasMore, lastID: l.LastID}, nil
+	}, func(batch Batch) string { return batch.ID })
+}
+
+// CancelBatch cancels an in-progress batch. It is cancelling for up to ten
+// minutes before it is cancelled, and its partial results are kept.
+func (b *BatchesAPI) CancelBatch(ctx context.Context, id string) (*Batch, *Response, error) {
+	u := fmt.Sprintf("v1/batches/%s/cancel", id)
+	req, err := b.openAIClient.NewRequest(http.MethodPost, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	batch := new(Batch)
+
+	resp, err := b.openAIClient.Do(ctx, req, batch)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return batch, resp, nil
+}
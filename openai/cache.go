@@ -0,0 +1,283 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AGMETEOR/openai-go/openai/internal/atomicfile"
+)
+
+// Cache stores raw response bodies keyed by request.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the body stored under key, if present and not expired.
+	Get(key string) ([]byte, bool)
+	// Set stores body under key. A zero ttl means the entry does not expire.
+	Set(key string, body []byte, ttl time.Duration)
+}
+
+// cacheKey identifies a request by its URL, the account it is sent for and
+// its canonicalized JSON body, so that field order and whitespace do not
+// affect the key while different endpoints and accounts never share entries.
+// The credentials are hashed so that keys, which DiskCache writes out, never
+// hold them.
+func cacheKey(req *http.Request, body interface{}) (string, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	var generic interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return "", err
+	}
+	// Maps are marshaled with sorted keys.
+	canonical, err := json.Marshal(generic)
+	if err != nil {
+		return "", err
+	}
+
+	account := sha256.New()
+	for _, h := range []string{"Authorization", "OpenAI-Organization", "OpenAI-Project"} {
+		io.WriteString(account, h+": "+req.Header.Get(h)+"\n")
+	}
+	return req.Method + " " + req.URL.String() + " " + hex.EncodeToString(account.Sum(nil)) + " " + string(canonical), nil
+}
+
+// deterministic reports whether a sampled request may be served from cache:
+// either the temperature is explicitly 0 or a seed is pinned. An unset
+// temperature samples at the API's default of 1.
+func deterministic(temperature *float64, seed *int) bool {
+	return seed != nil || (temperature != nil && *temperature == 0)
+}
+
+// pinnedModel reports whether model names a fixed snapshot rather than an
+// alias that moves to newer models, like the default.
+func pinnedModel(model string) bool {
+	return model != "" && !strings.HasSuffix(model, "-latest")
+}
+
+// doCached is Do with the client's Cache in front of it. Only successful
+// responses are stored. When cacheable is false or no cache is configured
+// the request is sent as is.
+func (c *OpenAIClient) doCached(ctx context.Context, req *http.Request, body interface{}, cacheable bool, v interface{}) (*Response, error) {
+	if c.Cache == nil || !cacheable {
+		return c.Do(ctx, req, v)
+	}
+
+	key, err := cacheKey(req, body)
+	if err != nil {
+		return c.Do(ctx, req, v)
+	}
+
+	if cached, ok := c.Cache.Get(key); ok {
+		if err := decodeBody(cached, v); err == nil {
+			return &Response{
+				Response: &http.Response{
+					Status:     "200 OK",
+					StatusCode: http.StatusOK,
+					Proto:      "HTTP/1.1",
+					ProtoMajor: 1,
+					ProtoMinor: 1,
+					Header:     http.Header{"Content-Type": {"application/json"}},
+					Body:       http.NoBody,
+					Request:    req,
+				},
+				CacheHit: true,
+			}, nil
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	resp, err := c.Do(ctx, req, buf)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		c.Cache.Set(key, buf.Bytes(), c.CacheTTL)
+	}
+	return resp, decodeBody(buf.Bytes(), v)
+}
+
+func decodeBody(body []byte, v interface{}) error {
+	err := json.Unmarshal(body, v)
+	if len(bytes.TrimSpace(body)) == 0 {
+		err = nil // ignore errors caused by empty response body
+	}
+	return err
+}
+
+// LRUCache is an in-memory Cache that evicts the least recently used entry
+// once it holds its capacity.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	entries  map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	body    []byte
+	expires time.Time
+}
+
+// NewLRUCache returns an LRUCache holding at most capacity entries.
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &LRUCache{
+		capacity: capacity,
+		ll:       list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get implements Cache.
+func (l *LRUCache) Get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*lruEntry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		l.ll.Remove(el)
+		delete(l.entries, key)
+		return nil, false
+	}
+	l.ll.MoveToFront(el)
+	return e.body, true
+}
+
+// Set implements Cache.
+func (l *LRUCache) Set(key string, body []byte, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e := &lruEntry{key: key, body: append([]byte(nil), body...)}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+
+	if el, ok := l.entries[key]; ok {
+		el.Value = e
+		l.ll.MoveToFront(el)
+		return
+	}
+	l.entries[key] = l.ll.PushFront(e)
+	for l.ll.Len() > l.capacity {
+		oldest := l.ll.Back()
+		l.ll.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of entries in the cache.
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ll.Len()
+}
+
+// DiskCache is a Cache that keeps one file per entry in a directory, so that
+// cached responses survive restarts.
+type DiskCache struct {
+	dir string
+}
+
+type diskEntry struct {
+	Key     string    `json:"key"`
+	Expires time.Time `json:"expires,omitempty"`
+	Body    []byte    `json:"body"`
+}
+
+// NewDiskCache returns a DiskCache storing its entries in dir, creating it if needed.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get implements Cache.
+func (d *DiskCache) Get(key string) ([]byte, bool) {
+	p := d.path(key)
+	raw, err := os.ReadFile(p)
+	if err != nil {
+		return nil, false
+	}
+	var e diskEntry
+	if err := json.Unmarshal(raw, &e); err != nil || e.Key != key {
+		return nil, false
+	}
+	if !e.Expires.IsZero() && time.Now().After(e.Expires) {
+		os.Remove(p)
+		return nil, false
+	}
+	return e.Body, true
+}
+
+// Set implements Cache. Entries are written to a temporary file and renamed
+// into place so concurrent readers never see a partial entry.
+func (d *DiskCache) Set(key string, body []byte, ttl time.Duration) {
+	e := diskEntry{Key: key, Body: body}
+	if ttl > 0 {
+		e.Expires = time.Now().Add(ttl)
+	}
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	atomicfile.WriteFile(d.path(key), raw)
+}
+
+// Prune removes the expired entries from the cache directory.
+func (d *DiskCache) Prune() error {
+	return filepath.WalkDir(d.dir, func(p string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if de.IsDir() || filepath.Ext(p) != ".json" {
+			return nil
+		}
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		var e diskEntry
+		if json.Unmarshal(raw, &e) != nil || (!e.Expires.IsZero() && time.Now().After(e.Expires)) {
+			os.Remove(p)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// cachingClient returns a client using cache whose requests are all answered
+// with a chat completion, and the number of requests that reached the server.
+func cachingClient(t *testing.T, cache Cache) (*OpenAIClient, *int32) {
+	t.Helper()
+	var calls int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"chatcmpl-%d","choices":[{"message":{"role":"assistant","content":"hi"}}]}`, n)
+	}))
+	c.Cache = cache
+	return c, &calls
+}
+
+func TestCacheOnlyDeterministicRequests(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	seed := 7
+	tests := []struct {
+		name      string
+		req       ChatRequest
+		wantCalls int32
+	}{
+		{"unset temperature", ChatRequest{Model: "gpt-4o"}, 2},
+		{"positive temperature", ChatRequest{Model: "gpt-4o", Temperature: Float64(0.7)}, 2},
+		{"zero temperature", ChatRequest{Model: "gpt-4o", Temperature: Float64(0)}, 1},
+		{"pinned seed", ChatRequest{Model: "gpt-4o", Temperature: Float64(0.7), Seed: &seed}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, calls := cachingClient(t, NewLRUCache(10))
+			for i := 0; i < 2; i++ {
+				req := tt.req
+				req.Messages = []Message{{Role: RoleUser, Content: "hello"}}
+				if _, _, err := c.Chat.CreateChatCompletion(context.Background(), &req); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := atomic.LoadInt32(calls); got != tt.wantCalls {
+				t.Errorf("server saw %d requests, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCacheModerationsWithPinnedModel(t *testing.T) {
+	tests := []struct {
+		model     string
+		wantCalls int32
+	}{
+		{"", 2},
+		{"omni-moderation-latest", 2},
+		{"omni-moderation-2024-09-26", 1},
+	}
+	for _, tt := range tests {
+		c, calls := cachingClient(t, NewLRUCache(10))
+		for i := 0; i < 2; i++ {
+			if _, _, err := c.Moderations.CreateModeration(context.Background(), &ContentModerationInput{Input: "hello", Model: tt.model}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := atomic.LoadInt32(calls); got != tt.wantCalls {
+			t.Errorf("model %q: server saw %d requests, want %d", tt.model, got, tt.wantCalls)
+		}
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	d, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Set("fresh", []byte(`{"a":1}`), 0)
+	if body, ok := d.Get("fresh"); !ok || string(body) != `{"a":1}` {
+		t.Errorf("Get(fresh) = %s, %v", body, ok)
+	}
+	if _, ok := d.Get("missing"); ok {
+		t.Error("Get(missing) found an entry")
+	}
+	d.Set("stale", []byte(`{"b":2}`), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if err := d.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := d.Get("stale"); ok {
+		t.Error("Get(stale) found an expired entry")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("cache directory holds %d files after Prune, want 1", len(entries))
+	}
+}
+
+func TestTemperatureZeroIsSent(t *testing.T) {
+	c := NewClient(nil)
+	for temperature, want := range map[*float64]string{
+		nil:          `"model":"gpt-4o","messages":null}`,
+		Float64(0):   `"temperature":0`,
+		Float64(0.5): `"temperature":0.5`,
+	} {
+		req, err := c.NewRequest(http.MethodPost, "v1/chat/completions", &ChatRequest{Model: "gpt-4o", Temperature: temperature})
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(body), want) {
+			t.Errorf("body %s does not contain %s", body, want)
+		}
+	}
+}
+
+func TestCacheKeyScopedToAccountAndBaseURL(t *testing.T) {
+	cache := NewLRUCache(10)
+	a, callsA := cachingClient(t, cache)
+	b, callsB := cachingClient(t, cache)
+	chat := func(c *OpenAIClient) {
+		t.Helper()
+		req := &ChatRequest{Model: "gpt-4o", Temperature: Float64(0), Messages: []Message{{Role: RoleUser, Content: "hello"}}}
+		if _, _, err := c.Chat.CreateChatCompletion(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv("OPENAI_API_KEY", "sk-one")
+	chat(a)
+	chat(b)
+	if atomic.LoadInt32(callsB) != 1 {
+		t.Error("a different base URL was served from the cache")
+	}
+
+	t.Setenv("OPENAI_API_KEY", "sk-two")
+	chat(a)
+	if atomic.LoadInt32(callsA) != 2 {
+		t.Error("a different API key was served from the cache")
+	}
+
+	chat(a)
+	if atomic.LoadInt32(callsA) != 2 {
+		t.Error("a repeated request was not served from the cache")
+	}
+
+	key, err := cacheKey(mustRequest(t, "sk-secret"), map[string]int{"b": 1, "a": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(key, "sk-secret") {
+		t.Errorf("cache key %q holds the API key", key)
+	}
+}
+
+func mustRequest(t *testing.T, apiKey string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, "https://api.openai.com/v1/chat/completions", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	return req
+}
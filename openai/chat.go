@@ -9,10 +9,17 @@ import (
 
 type ChatAPI Api
 
+// Roles of the participants in a chat conversation.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
 type ChatRequest struct {
 	Model            string             `json:"model" binding:"required"`
-	Messages         []string           `json:"messages" binding:"required"`
-	Temperature      float64            `json:"temperature,omitempty"`
+	Messages         []Message          `json:"messages" binding:"required"`
+	Temperature      *float64           `json:"temperature,omitempty"`
 	TopP             float64            `json:"top_p,omitempty"`
 	N                int                `json:"n,omitempty"`
 	Stream           bool               `json:"stream,omitempty"`
@@ -22,12 +29,14 @@ type ChatRequest struct {
 	FrequencyPenalty float64            `json:"frequency_penalty,omitempty"`
 	LogitBias        map[string]float64 `json:"logit_bias,omitempty"`
 	User             string             `json:"user,omitempty"`
+	Seed             *int               `json:"seed,omitempty"`
 }
 
 type ChatCompletion struct {
 	ID      string   `json:"id"`
 	Object  string   `json:"object"`
 	Created int64    `json:"created"`
+	Model   string   `json:"model"`
 	Choices []Choice `json:"choices"`
 	Usage   Usage    `json:"usage"`
 }
@@ -41,12 +50,7 @@ type Choice struct {
 type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
-}
-
-type Usage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	Name    string `json:"name,omitempty"`
 }
 
 // CreateChatCompletion creates a completion for the chat message
@@ -57,12 +61,21 @@ func (c *ChatAPI) CreateChatCompletion(ctx context.Context, chatReq *ChatRequest
 		return nil, nil, err
 	}
 
+	if err := c.openAIClient.checkUsage(ctx, EndpointChatCompletions, chatReq.Model, chatReq.User); err != nil {
+		return nil, nil, err
+	}
+
 	chatCompletion := new(ChatCompletion)
 
-	resp, err := c.openAIClient.Do(ctx, req, chatCompletion)
+	cacheable := !chatReq.Stream && deterministic(chatReq.Temperature, chatReq.Seed)
+	resp, err := c.openAIClient.doCached(ctx, req, chatReq, cacheable, chatCompletion)
 	if err != nil {
 		return nil, resp, err
 	}
 
+	if !resp.CacheHit {
+		c.openAIClient.recordUsage(ctx, EndpointChatCompletions, firstNonEmpty(chatCompletion.Model, chatReq.Model), chatReq.User, chatCompletion.Usage)
+	}
+
 	return chatCompletion, resp, nil
 }
@@ -0,0 +1,210 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AGMETEOR/openai-go/openai/tokenizer"
+)
+
+// ErrContextWindowExceeded is returned when a conversation cannot be made to fit
+// in the model's context window.
+var ErrContextWindowExceeded = errors.New("openai: conversation does not fit in the context window")
+
+// CountChatTokens returns the number of prompt tokens messages take up when
+// sent to model, including the per-message framing the chat format adds and
+// the tokens that prime the assistant's reply.
+func CountChatTokens(model string, messages []Message) (int, error) {
+	enc, err := tokenizer.EncodingForModel(model)
+	if err != nil {
+		return 0, err
+	}
+
+	tokensPerMessage, tokensPerName := 3, 1
+	if model == "gpt-3.5-turbo-0301" {
+		// Every message follows <|start|>{role/name}\n{content}<|end|>\n,
+		// and when a name is present the role is omitted.
+		tokensPerMessage, tokensPerName = 4, -1
+	}
+
+	n := 0
+	for _, m := range messages {
+		n += tokensPerMessage
+		n += enc.Count(m.Role)
+		n += enc.Count(m.Content)
+		if m.Name != "" {
+			n += enc.Count(m.Name) + tokensPerName
+		}
+	}
+	// Every reply is primed with <|start|>assistant<|message|>.
+	n += 3
+	return n, nil
+}
+
+// TruncationStrategy shortens a conversation until it takes up no more than
+// budget prompt tokens.
+type TruncationStrategy interface {
+	Truncate(ctx context.Context, model string, messages []Message, budget int) ([]Message, error)
+}
+
+// TruncateConversation shortens chatReq.Messages with strategy so that the
+// prompt and chatReq.MaxTokens together fit in the model's context window,
+// as recorded in the client's ModelRegistry.
+// The request is left unchanged when it already fits.
+func (c *ChatAPI) TruncateConversation(ctx context.Context, chatReq *ChatRequest, strategy TruncationStrategy) error {
+	info, ok := c.openAIClient.modelRegistry().Lookup(chatReq.Model)
+	if !ok || info.ContextWindow == 0 {
+		return fmt.Errorf("openai: unknown context window for model %q", chatReq.Model)
+	}
+
+	budget := info.ContextWindow - chatReq.MaxTokens
+	if budget <= 0 {
+		return fmt.Errorf("%w: max_tokens %d leaves no room for the prompt", ErrContextWindowExceeded, chatReq.MaxTokens)
+	}
+
+	n, err := CountChatTokens(chatReq.Model, chatReq.Messages)
+	if err != nil {
+		return err
+	}
+	if n <= budget {
+		return nil
+	}
+
+	if strategy == nil {
+		strategy = DropOldest{}
+	}
+	messages, err := strategy.Truncate(ctx, chatReq.Model, chatReq.Messages, budget)
+	if err != nil {
+		return err
+	}
+	chatReq.Messages = messages
+	return nil
+}
+
+// DropOldest removes the oldest non-system messages until the conversation fits.
+// System messages and the most recent message are always kept.
+type DropOldest struct{}
+
+// Truncate implements TruncationStrategy.
+func (DropOldest) Truncate(ctx context.Context, model string, messages []Message, budget int) ([]Message, error) {
+	out := append([]Message(nil), messages...)
+	for {
+		n, err := CountChatTokens(model, out)
+		if err != nil {
+			return nil, err
+		}
+		if n <= budget {
+			return out, nil
+		}
+		i := oldestDroppable(out)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %d tokens, budget %d", ErrContextWindowExceeded, n, budget)
+		}
+		out = append(out[:i], out[i+1:]...)
+	}
+}
+
+// oldestDroppable returns the index of the oldest non-system message that is
+// not the last message, or -1 if there is none.
+func oldestDroppable(messages []Message) int {
+	for i := 0; i < len(messages)-1; i++ {
+		if messages[i].Role != RoleSystem {
+			return i
+		}
+	}
+	return -1
+}
+
+// Summarizer condenses messages into a short piece of text.
+type Summarizer func(ctx context.Context, messages []Message) (string, error)
+
+// SummarizeOldest replaces the oldest non-system messages with a single system
+// message holding their summary. If the summarized conversation still does
+// not fit, further messages are dropped as with DropOldest.
+type SummarizeOldest struct {
+	Summarize Summarizer
+
+	// SummaryTokens is the number of tokens reserved for the summary.
+	// Defaults to 256.
+	SummaryTokens int
+}
+
+// Truncate implements TruncationStrategy.
+func (s SummarizeOldest) Truncate(ctx context.Context, model string, messages []Message, budget int) ([]Message, error) {
+	if s.Summarize == nil {
+		return nil, errors.New("openai: SummarizeOldest requires a Summarize function")
+	}
+	reserve := s.SummaryTokens
+	if reserve <= 0 {
+		reserve = 256
+	}
+
+	// Move the oldest messages into the summary until the rest fits next to it.
+	kept := append([]Message(nil), messages...)
+	var summarized []Message
+	for {
+		n, err := CountChatTokens(model, kept)
+		if err != nil {
+			return nil, err
+		}
+		if n+reserve <= budget {
+			break
+		}
+		i := oldestDroppable(kept)
+		if i < 0 {
+			break
+		}
+		summarized = append(summarized, kept[i])
+		kept = append(kept[:i], kept[i+1:]...)
+	}
+	if len(summarized) == 0 {
+		return DropOldest{}.Truncate(ctx, model, kept, budget)
+	}
+
+	summary, err := s.Summarize(ctx, summarized)
+	if err != nil {
+		return nil, err
+	}
+
+	// The summary goes after the leading system messages.
+	at := 0
+	for at < len(kept) && kept[at].Role == RoleSystem {
+		at++
+	}
+	out := make([]Message, 0, len(kept)+1)
+	out = append(out, kept[:at]...)
+	out = append(out, Message{Role: RoleSystem, Content: "Summary of the earlier conversation: " + summary})
+	out = append(out, kept[at:]...)
+
+	return DropOldest{}.Truncate(ctx, model, out, budget)
+}
+
+// NewSummarizer returns a Summarizer that asks model to summarize messages.
+func (c *ChatAPI) NewSummarizer(model string) Summarizer {
+	return func(ctx context.Context, messages []Message) (string, error) {
+		var transcript strings.Builder
+		for _, m := range messages {
+			fmt.Fprintf(&transcript, "%s: %s\n", m.Role, m.Content)
+		}
+
+		chatReq := &ChatRequest{
+			Model: model,
+			Messages: []Message{
+				{Role: RoleSystem, Content: "Summarize the following conversation in a few sentences. Keep names, facts and decisions."},
+				{Role: RoleUser, Content: transcript.String()},
+			},
+		}
+		completion, _, err := c.CreateChatCompletion(ctx, chatReq)
+		if err != nil {
+			return "", err
+		}
+		if len(completion.Choices) == 0 {
+			return "", errors.New("openai: summary request returned no choices")
+		}
+		return completion.Choices[0].Message.Content, nil
+	}
+}
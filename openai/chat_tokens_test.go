@@ -0,0 +1,159 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AGMETEOR/openai-go/openai/tokenizer"
+)
+
+// cookbookMessages is the example conversation from OpenAI's guide to
+// counting tokens for chat models.
+var cookbookMessages = []Message{
+	{Role: RoleSystem, Content: "You are a helpful, pattern-following assistant that translates corporate jargon into plain English."},
+	{Role: RoleSystem, Name: "example_user", Content: "New synergies will help drive top-line growth."},
+	{Role: RoleSystem, Name: "example_assistant", Content: "Things working well together will increase revenue."},
+	{Role: RoleSystem, Name: "example_user", Content: "Let's circle back when we have more bandwidth to touch base on opportunities for increased leverage."},
+	{Role: RoleSystem, Name: "example_assistant", Content: "Let's talk later when we're less busy about how to do better."},
+	{Role: RoleUser, Content: "This late pivot means we don't have time to boil the ocean for the client project."},
+}
+
+func TestCountChatTokens(t *testing.T) {
+	tests := map[string]int{
+		"gpt-3.5-turbo-0301": 126,
+		"gpt-3.5-turbo":      128,
+		"gpt-3.5-turbo-0125": 128,
+		"gpt-4":              128,
+		"gpt-4-0613":         128,
+		"gpt-4o":             123,
+		"gpt-4o-2024-08-06":  123,
+		"gpt-4o-mini":        123,
+	}
+	for model, want := range tests {
+		got, err := CountChatTokens(model, cookbookMessages)
+		if err != nil {
+			t.Fatalf("CountChatTokens(%q): %v", model, err)
+		}
+		if got != want {
+			t.Errorf("CountChatTokens(%q) = %d, want %d", model, got, want)
+		}
+	}
+}
+
+func TestCountChatTokensOverhead(t *testing.T) {
+	tests := []struct {
+		model                        string
+		tokensPerMessage, tokensName int
+	}{
+		{"gpt-3.5-turbo-0301", 4, -1},
+		{"gpt-3.5-turbo", 3, 1},
+		{"gpt-4", 3, 1},
+		{"gpt-4o", 3, 1},
+	}
+	for _, tt := range tests {
+		enc, err := tokenizer.EncodingForModel(tt.model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := Message{Role: RoleUser, Content: "hello world"}
+		// Every reply is primed with three tokens.
+		want := tt.tokensPerMessage + enc.Count(msg.Role) + enc.Count(msg.Content) + 3
+		if got, _ := CountChatTokens(tt.model, []Message{msg}); got != want {
+			t.Errorf("%s: unnamed message = %d tokens, want %d", tt.model, got, want)
+		}
+
+		msg.Name = "alice"
+		want += enc.Count(msg.Name) + tt.tokensName
+		if got, _ := CountChatTokens(tt.model, []Message{msg}); got != want {
+			t.Errorf("%s: named message = %d tokens, want %d", tt.model, got, want)
+		}
+	}
+}
+
+func TestCountChatTokensUnknownModel(t *testing.T) {
+	if _, err := CountChatTokens("my-deployment", cookbookMessages); err == nil {
+		t.Error("CountChatTokens of an unknown model succeeded")
+	}
+}
+
+func conversation(turns int) []Message {
+	messages := []Message{{Role: RoleSystem, Content: "You are terse."}}
+	for i := 0; i < turns; i++ {
+		messages = append(messages,
+			Message{Role: RoleUser, Content: "Tell me something about the number " + strings.Repeat("seven ", 5)},
+			Message{Role: RoleAssistant, Content: "It is prime, and it is odd, and it comes after six."},
+		)
+	}
+	return append(messages, Message{Role: RoleUser, Content: "And eight?"})
+}
+
+func TestTruncateConversationUsesClientRegistry(t *testing.T) {
+	registry := NewModelRegistry()
+	registry.Register(ModelInfo{ID: "gpt-4", ContextWindow: 120})
+	client := NewClient(nil)
+	client.ModelRegistry = registry
+
+	chatReq := &ChatRequest{Model: "gpt-4", Messages: conversation(5), MaxTokens: 20}
+	before := len(chatReq.Messages)
+	if err := client.Chat.TruncateConversation(context.Background(), chatReq, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(chatReq.Messages) >= before {
+		t.Fatalf("conversation was not truncated to the client's context window: %d messages", len(chatReq.Messages))
+	}
+	n, _ := CountChatTokens("gpt-4", chatReq.Messages)
+	if n > 100 {
+		t.Errorf("truncated prompt is %d tokens, want at most 100", n)
+	}
+	if chatReq.Messages[0].Role != RoleSystem || chatReq.Messages[len(chatReq.Messages)-1].Content != "And eight?" {
+		t.Errorf("system prompt or last message dropped: %+v", chatReq.Messages)
+	}
+
+	// The default registry knows gpt-4's real window, so nothing is dropped.
+	chatReq = &ChatRequest{Model: "gpt-4", Messages: conversation(5), MaxTokens: 20}
+	if err := NewClient(nil).Chat.TruncateConversation(context.Background(), chatReq, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(chatReq.Messages) != before {
+		t.Errorf("default registry truncated to %d messages, want %d", len(chatReq.Messages), before)
+	}
+}
+
+func TestDropOldestCannotFit(t *testing.T) {
+	_, err := DropOldest{}.Truncate(context.Background(), "gpt-4", conversation(0), 5)
+	if !errors.Is(err, ErrContextWindowExceeded) {
+		t.Errorf("err = %v, want ErrContextWindowExceeded", err)
+	}
+}
+
+func TestSummarizeOldest(t *testing.T) {
+	var summarized []Message
+	s := SummarizeOldest{
+		Summarize: func(ctx context.Context, messages []Message) (string, error) {
+			summarized = messages
+			return "they talked about seven", nil
+		},
+		SummaryTokens: 20,
+	}
+	messages := conversation(5)
+	out, err := s.Truncate(context.Background(), "gpt-4o", messages, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summarized) == 0 {
+		t.Fatal("nothing was summarized")
+	}
+	if out[0].Content != messages[0].Content {
+		t.Errorf("first message = %q, want the original system prompt", out[0].Content)
+	}
+	if out[1].Role != RoleSystem || !strings.Contains(out[1].Content, "they talked about seven") {
+		t.Errorf("second message = %+v, want the summary", out[1])
+	}
+	if n, _ := CountChatTokens("gpt-4o", out); n > 100 {
+		t.Errorf("summarized prompt is %d tokens, want at most 100", n)
+	}
+}
@@ -14,7 +14,7 @@ type CompletionRequest struct {
 	Prompt           interface{} `json:"prompt,omitempty"`
 	Suffix           string      `json:"suffix,omitempty"`
 	MaxTokens        int         `json:"max_tokens,omitempty"`
-	Temperature      float64     `json:"temperature,omitempty"`
+	Temperature      *float64    `json:"temperature,omitempty"`
 	TopP             float64     `json:"top_p,omitempty"`
 	N                int         `json:"n,omitempty"`
 	Stream           bool        `json:"stream,omitempty"`
@@ -26,6 +26,7 @@ type CompletionRequest struct {
 	BestOf           int         `json:"best_of,omitempty"`
 	LogitBias        interface{} `json:"logit_bias,omitempty"`
 	User             string      `json:"user,omitempty"`
+	Seed             *int        `json:"seed,omitempty"`
 }
 
 type Completion struct {
@@ -34,7 +35,7 @@ type Completion struct {
 	Created int64        `json:"created"`
 	Model   string       `json:"model"`
 	Choices []TextChoice `json:"choices"`
-	Usage   TextUsage    `json:"usage"`
+	Usage   Usage        `json:"usage"`
 }
 
 type TextChoice struct {
@@ -44,11 +45,8 @@ type TextChoice struct {
 	FinishReason string      `json:"finish_reason"`
 }
 
-type TextUsage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
-}
+// Deprecated: Use Usage.
+type TextUsage = Usage
 
 // CreateCompletion creates a completion for the provided prompt and parameters
 func (c *CompletionsAPI) CreateCompletion(ctx context.Context, completionReq *CompletionRequest) (*Completion, *Response, error) {
@@ -58,12 +56,21 @@ func (c *CompletionsAPI) CreateCompletion(ctx context.Context, completionReq *Co
 		return nil, nil, err
 	}
 
+	if err := c.openAIClient.checkUsage(ctx, EndpointCompletions, completionReq.Model, completionReq.User); err != nil {
+		return nil, nil, err
+	}
+
 	completion := new(Completion)
 
-	resp, err := c.openAIClient.Do(ctx, req, completion)
+	cacheable := !completionReq.Stream && deterministic(completionReq.Temperature, completionReq.Seed)
+	resp, err := c.openAIClient.doCached(ctx, req, completionReq, cacheable, completion)
 	if err != nil {
 		return nil, resp, err
 	}
 
+	if !resp.CacheHit {
+		c.openAIClient.recordUsage(ctx, EndpointCompletions, firstNonEmpty(completion.Model, completionReq.Model), completionReq.User, completion.Usage)
+	}
+
 	return completion, resp, nil
 }
@@ -10,19 +10,19 @@ import (
 type EditsAPI Api
 
 type EditRequest struct {
-	Model       string  `json:"model" binding:"required"`
-	Input       string  `json:"input,omitempty"`
-	Instruction string  `json:"instruction" binding:"required"`
-	N           int     `json:"n,omitempty" default:"1"`
-	Temperature float64 `json:"temperature,omitempty" default:"1"`
-	TopP        float64 `json:"top_p,omitempty" default:"1"`
+	Model       string   `json:"model" binding:"required"`
+	Input       string   `json:"input,omitempty"`
+	Instruction string   `json:"instruction" binding:"required"`
+	N           int      `json:"n,omitempty" default:"1"`
+	Temperature *float64 `json:"temperature,omitempty" default:"1"`
+	TopP        float64  `json:"top_p,omitempty" default:"1"`
 }
 
 type EditedInput struct {
 	Object  string         `json:"object"`
 	Created int64          `json:"created"`
 	Choices []EditedChoice `json:"choices"`
-	Usage   EditedUsage    `json:"usage"`
+	Usage   Usage          `json:"usage"`
 }
 
 type EditedChoice struct {
@@ -30,11 +30,8 @@ type EditedChoice struct {
 	Index int    `json:"index"`
 }
 
-type EditedUsage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
-}
+// Deprecated: Use Usage.
+type EditedUsage = Usage
 
 // CreateEdit creates a new edit for the provided input, instruction, and parameters.
 func (e *EditsAPI) CreateEdit(ctx context.Context, editReq *EditRequest) (*EditedInput, *Response, error) {
@@ -44,12 +41,20 @@ func (e *EditsAPI) CreateEdit(ctx context.Context, editReq *EditRequest) (*Edite
 		return nil, nil, err
 	}
 
+	if err := e.openAIClient.checkUsage(ctx, EndpointEdits, editReq.Model, ""); err != nil {
+		return nil, nil, err
+	}
+
 	edited := new(EditedInput)
 
-	resp, err := e.openAIClient.Do(ctx, req, edited)
+	resp, err := e.openAIClient.doCached(ctx, req, editReq, deterministic(editReq.Temperature, nil), edited)
 	if err != nil {
 		return nil, resp, err
 	}
 
+	if !resp.CacheHit {
+		e.openAIClient.recordUsage(ctx, EndpointEdits, editReq.Model, "", edited.Usage)
+	}
+
 	return edited, resp, nil
 }
@@ -4,49 +4,174 @@ package openai
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
 )
 
 type EmbeddingsAPI Api
 
+// Formats in which embeddings can be returned.
+const (
+	EmbeddingEncodingFloat  = "float"
+	EmbeddingEncodingBase64 = "base64"
+)
+
 type EmbeddingRequest struct {
-	Model string      `json:"model" binding:"required"`
-	Input interface{} `json:"input" binding:"required"`
-	User  string      `json:"user,omitempty"`
+	Model string         `json:"model" binding:"required"`
+	Input EmbeddingInput `json:"input" binding:"required"`
+	// EncodingFormat is "float" (the default) or "base64". Base64 responses
+	// are smaller on the wire and are decoded into the same []float32.
+	EncodingFormat string `json:"encoding_format,omitempty"`
+	// Dimensions truncates the embeddings to this many dimensions.
+	// Only supported by text-embedding-3 and later models.
+	Dimensions int    `json:"dimensions,omitempty"`
+	User       string `json:"user,omitempty"`
+}
+
+// EmbeddingInput is the text or tokens to embed. Use EmbeddingText,
+// EmbeddingTexts, EmbeddingTokens or EmbeddingTokenBatch to build one.
+type EmbeddingInput struct {
+	value interface{}
+	n     int
+}
+
+// EmbeddingText embeds a single string.
+func EmbeddingText(text string) EmbeddingInput {
+	return EmbeddingInput{value: text, n: 1}
+}
+
+// EmbeddingTexts embeds each string of texts.
+func EmbeddingTexts(texts []string) EmbeddingInput {
+	return EmbeddingInput{value: texts, n: len(texts)}
+}
+
+// EmbeddingTokens embeds a single array of tokens.
+func EmbeddingTokens(tokens []int) EmbeddingInput {
+	return EmbeddingInput{value: tokens, n: 1}
+}
+
+// EmbeddingTokenBatch embeds each array of tokens of batch.
+func EmbeddingTokenBatch(batch [][]int) EmbeddingInput {
+	return EmbeddingInput{value: batch, n: len(batch)}
+}
+
+// Len returns the number of embeddings the input produces.
+func (in EmbeddingInput) Len() int {
+	return in.n
+}
+
+func (in EmbeddingInput) MarshalJSON() ([]byte, error) {
+	if in.value == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(in.value)
+}
+
+func (in *EmbeddingInput) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*in = EmbeddingText(text)
+		return nil
+	}
+	var texts []string
+	if err := json.Unmarshal(data, &texts); err == nil {
+		*in = EmbeddingTexts(texts)
+		return nil
+	}
+	var tokens []int
+	if err := json.Unmarshal(data, &tokens); err == nil {
+		*in = EmbeddingTokens(tokens)
+		return nil
+	}
+	var batch [][]int
+	if err := json.Unmarshal(data, &batch); err == nil {
+		*in = EmbeddingTokenBatch(batch)
+		return nil
+	}
+	return fmt.Errorf("openai: unsupported embedding input %s", data)
 }
 
 type EmbeddingResponse struct {
-	Object string   `json:"object"`
-	Data   []Embed  `json:"data"`
-	Model  string   `json:"model"`
-	Usage  EmbedUse `json:"usage"`
+	Object string  `json:"object"`
+	Data   []Embed `json:"data"`
+	Model  string  `json:"model"`
+	Usage  Usage   `json:"usage"`
 }
 
 type Embed struct {
 	Object    string    `json:"object"`
-	Embedding []float64 `json:"embedding"`
+	Embedding []float32 `json:"embedding"`
 	Index     int       `json:"index"`
 }
 
-type EmbedUse struct {
-	PromptTokens int `json:"prompt_tokens"`
-	TotalTokens  int `json:"total_tokens"`
+// Deprecated: Use Usage.
+type EmbedUse = Usage
+
+func (e *Embed) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Object    string          `json:"object"`
+		Embedding json.RawMessage `json:"embedding"`
+		Index     int             `json:"index"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	e.Object, e.Index = raw.Object, raw.Index
+
+	var encoded string
+	if err := json.Unmarshal(raw.Embedding, &encoded); err != nil {
+		return json.Unmarshal(raw.Embedding, &e.Embedding)
+	}
+	vec, err := decodeBase64Embedding(encoded)
+	if err != nil {
+		return err
+	}
+	e.Embedding = vec
+	return nil
+}
+
+// decodeBase64Embedding decodes a base64 encoded array of little-endian float32s.
+func decodeBase64Embedding(s string) ([]float32, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("openai: decoding base64 embedding: %w", err)
+	}
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("openai: base64 embedding has %d bytes, not a multiple of 4", len(b))
+	}
+	vec := make([]float32, len(b)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+	return vec, nil
 }
 
 // CreateEmbeddings creates an embedding vector representing the input text
 func (em *EmbeddingsAPI) CreateEmbeddings(ctx context.Context, embReq *EmbeddingRequest) (*EmbeddingResponse, *Response, error) {
-	u := "v1/images/embeddings"
+	u := "v1/embeddings"
 	req, err := em.openAIClient.NewRequest(http.MethodPost, u, embReq)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if err := em.openAIClient.checkUsage(ctx, EndpointEmbeddings, embReq.Model, embReq.User); err != nil {
+		return nil, nil, err
+	}
+
 	embResp := new(EmbeddingResponse)
 
-	resp, err := em.openAIClient.Do(ctx, req, embResp)
+	resp, err := em.openAIClient.doCached(ctx, req, embReq, true, embResp)
 	if err != nil {
 		return nil, resp, err
 	}
 
+	if !resp.CacheHit {
+		em.openAIClient.recordUsage(ctx, EndpointEmbeddings, firstNonEmpty(embResp.Model, embReq.Model), embReq.User, embResp.Usage)
+	}
+
 	return embResp, resp, nil
 }
@@ -0,0 +1,203 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+// Package chunk splits documents into token-bounded pieces for embedding and
+// retrieval. Every chunk records the byte offsets it was taken from.
+package chunk
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/AGMETEOR/openai-go/openai/tokenizer"
+)
+
+// Chunk is a piece of a source text.
+type Chunk struct {
+	Text string
+	// Start and End are the byte offsets of Text in the source: Text == source[Start:End].
+	Start, End int
+	Tokens     int
+	// Heading is the path of Markdown headings the chunk falls under,
+	// joined with " > ". It is only set by the Markdown splitter.
+	Heading string
+}
+
+// Splitter breaks a text into chunks.
+type Splitter interface {
+	Split(text string) []Chunk
+}
+
+// DefaultEmbeddingModel is the model whose tokenizer counts tokens when
+// Options.Count is not set.
+const DefaultEmbeddingModel = "text-embedding-3-small"
+
+// Options configures the splitters.
+type Options struct {
+	// Size is the maximum number of tokens per chunk. Defaults to 512.
+	Size int
+	// Overlap is the number of tokens of the previous chunk repeated at the
+	// start of the next one. Must be smaller than Size. Defaults to 0.
+	Overlap int
+	// Count returns the number of tokens in a text. Defaults to the
+	// tokenizer of DefaultEmbeddingModel.
+	Count func(string) int
+}
+
+func (o Options) withDefaults() Options {
+	if o.Size <= 0 {
+		o.Size = 512
+	}
+	if o.Overlap < 0 || o.Overlap >= o.Size {
+		o.Overlap = 0
+	}
+	if o.Count == nil {
+		o.Count = tokenizer.CounterForModelOrEstimate(DefaultEmbeddingModel)
+	}
+	return o
+}
+
+// Texts returns the text of each chunk, ready to pass to EmbeddingsAPI.EmbedAll.
+func Texts(chunks []Chunk) []string {
+	out := make([]string, len(chunks))
+	for i, c := range chunks {
+		out[i] = c.Text
+	}
+	return out
+}
+
+// segment is a contiguous span of the source that is never split further
+// when packing chunks.
+type segment struct {
+	start, end int
+	tokens     int
+}
+
+// pack greedily combines consecutive segments into chunks of at most
+// opts.Size tokens, starting each chunk with up to opts.Overlap tokens of
+// trailing segments from the previous one. Segment token counts are summed to
+// decide where to cut, but tokens can merge across segment boundaries, so
+// every chunk is counted again and shortened if the sum was too optimistic.
+func pack(src string, segs []segment, opts Options) []Chunk {
+	var chunks []Chunk
+	// emit adds the chunk for segs[from:to], dropping trailing segments while
+	// it is over opts.Size, and returns the end of the segments it used.
+	emit := func(from, to int) int {
+		c := newChunk(src, segs[from].start, segs[to-1].end, opts)
+		for c.Tokens > opts.Size && to > from+1 {
+			to--
+			c = newChunk(src, segs[from].start, segs[to-1].end, opts)
+		}
+		if c.Text != "" {
+			chunks = append(chunks, c)
+		}
+		return to
+	}
+	// carry returns where the chunk after segs[first:i] starts: trailing
+	// segments are repeated as overlap as long as they leave room for segs[i].
+	carry := func(first, i int) (next, carried int) {
+		next = i
+		for next > first+1 && carried+segs[next-1].tokens <= opts.Overlap &&
+			carried+segs[next-1].tokens+segs[i].tokens <= opts.Size {
+			next--
+			carried += segs[next].tokens
+		}
+		return next, carried
+	}
+
+	first, tokens := 0, 0
+	for i := 0; i < len(segs); i++ {
+		if i > first && tokens+segs[i].tokens > opts.Size {
+			i = emit(first, i)
+			first, tokens = carry(first, i)
+		}
+		tokens += segs[i].tokens
+	}
+	for first < len(segs) {
+		end := emit(first, len(segs))
+		if end == len(segs) {
+			break
+		}
+		first, _ = carry(first, end)
+	}
+	return chunks
+}
+
+// newChunk builds the chunk for src[start:end] with surrounding whitespace trimmed.
+func newChunk(src string, start, end int, opts Options) Chunk {
+	text := src[start:end]
+	trimmedLeft := strings.TrimLeftFunc(text, unicode.IsSpace)
+	start += len(text) - len(trimmedLeft)
+	text = strings.TrimRightFunc(trimmedLeft, unicode.IsSpace)
+	end = start + len(text)
+	return Chunk{Text: text, Start: start, End: end, Tokens: opts.Count(text)}
+}
+
+// words splits src[start:end] into segments each holding a word and the
+// whitespace before it, splitting any segment over opts.Size tokens.
+func words(src string, start, end int, opts Options) []segment {
+	var segs []segment
+	segStart := start
+	inSpace := true
+	for i, r := range src[start:end] {
+		i += start
+		space := unicode.IsSpace(r)
+		if space && !inSpace && i > segStart {
+			segs = appendBounded(segs, src, segStart, i, opts)
+			segStart = i
+		}
+		inSpace = space
+	}
+	if segStart < end {
+		segs = appendBounded(segs, src, segStart, end, opts)
+	}
+	return segs
+}
+
+// appendBounded appends src[start:end] as one segment, or as several cut at
+// rune boundaries when it is longer than opts.Size tokens.
+func appendBounded(segs []segment, src string, start, end int, opts Options) []segment {
+	n := opts.Count(src[start:end])
+	if n <= opts.Size {
+		return append(segs, segment{start: start, end: end, tokens: n})
+	}
+
+	for start < end {
+		// Shrink a proportional guess until it fits.
+		cut := start + (end-start)*opts.Size/n
+		if cut <= start {
+			cut = start + 1
+		}
+		for cut < end && !utf8.RuneStart(src[cut]) {
+			cut++
+		}
+		for cut > start+1 && opts.Count(src[start:cut]) > opts.Size {
+			cut = start + (cut-start)*9/10
+			for cut > start+1 && !utf8.RuneStart(src[cut]) {
+				cut--
+			}
+		}
+		for cut < end && !utf8.RuneStart(src[cut]) {
+			cut++
+		}
+		segs = append(segs, segment{start: start, end: cut, tokens: opts.Count(src[start:cut])})
+		start = cut
+	}
+	return segs
+}
+
+// fixedSize packs words into windows of a fixed number of tokens.
+type fixedSize struct {
+	opts Options
+}
+
+// NewFixedSize returns a Splitter producing chunks of up to opts.Size tokens
+// that overlap by opts.Overlap tokens, cutting only between words unless a
+// single word is too long.
+func NewFixedSize(opts Options) Splitter {
+	return fixedSize{opts: opts.withDefaults()}
+}
+
+func (f fixedSize) Split(text string) []Chunk {
+	return pack(text, words(text, 0, len(text), f.opts), f.opts)
+}
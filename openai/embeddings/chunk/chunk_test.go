@@ -0,0 +1,143 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package chunk
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// wordCount counts one token per word.
+func wordCount(s string) int {
+	return len(strings.Fields(s))
+}
+
+// mergingCount counts more tokens for joined words than for each word on its
+// own, so that summing the segments underestimates a chunk.
+func mergingCount(s string) int {
+	if n := wordCount(s); n > 0 {
+		return 2*n - 1
+	}
+	return 0
+}
+
+const sampleMarkdown = `# Guide
+
+Intro paragraph with a handful of words. It has two sentences!
+
+## Setup
+
+Install the tool, then configure it.
+Run it once to check that it works.
+
+` + "```" + `
+# not a heading
+go run ./cmd/tool
+` + "```" + `
+
+## Usage
+
+Call the tool with a file; it prints a summary of the file.
+`
+
+func splitters(opts Options) map[string]Splitter {
+	return map[string]Splitter{
+		"fixed":     NewFixedSize(opts),
+		"recursive": NewRecursive(opts),
+		"markdown":  NewMarkdown(opts),
+	}
+}
+
+func TestChunkOffsets(t *testing.T) {
+	for name, s := range splitters(Options{Size: 8, Overlap: 2}) {
+		chunks := s.Split(sampleMarkdown)
+		if len(chunks) < 2 {
+			t.Fatalf("%s: got %d chunks, want the text split", name, len(chunks))
+		}
+		covered := make([]bool, len(sampleMarkdown))
+		for i, c := range chunks {
+			if sampleMarkdown[c.Start:c.End] != c.Text {
+				t.Errorf("%s: chunk %d is %q, but its offsets hold %q", name, i, c.Text, sampleMarkdown[c.Start:c.End])
+			}
+			if c.Text != strings.TrimSpace(c.Text) {
+				t.Errorf("%s: chunk %d %q is not trimmed", name, i, c.Text)
+			}
+			if i > 0 && c.Start < chunks[i-1].Start {
+				t.Errorf("%s: chunk %d starts before chunk %d", name, i, i-1)
+			}
+			for j := c.Start; j < c.End; j++ {
+				covered[j] = true
+			}
+		}
+		for j, r := range sampleMarkdown {
+			if !covered[j] && strings.TrimSpace(string(r)) != "" {
+				t.Errorf("%s: byte %d %q is in no chunk", name, j, r)
+				break
+			}
+		}
+	}
+}
+
+func TestChunkOverlap(t *testing.T) {
+	var words []string
+	for i := 0; i < 10; i++ {
+		words = append(words, fmt.Sprintf("w%d", i))
+	}
+	text := strings.Join(words, " ")
+
+	tests := []struct {
+		overlap int
+		want    []string
+	}{
+		{0, []string{"w0 w1 w2 w3", "w4 w5 w6 w7", "w8 w9"}},
+		{1, []string{"w0 w1 w2 w3", "w3 w4 w5 w6", "w6 w7 w8 w9"}},
+		{2, []string{"w0 w1 w2 w3", "w2 w3 w4 w5", "w4 w5 w6 w7", "w6 w7 w8 w9"}},
+		{4, []string{"w0 w1 w2 w3", "w4 w5 w6 w7", "w8 w9"}}, // not smaller than Size: ignored
+	}
+	for _, tt := range tests {
+		chunks := NewFixedSize(Options{Size: 4, Overlap: tt.overlap, Count: wordCount}).Split(text)
+		if got := Texts(chunks); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("overlap %d: got %q, want %q", tt.overlap, got, tt.want)
+		}
+	}
+}
+
+func TestChunkSizeBound(t *testing.T) {
+	long := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 40) +
+		strings.Repeat("x", 300) + "\n\n" + sampleMarkdown
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"tokenizer", Options{Size: 16, Overlap: 4}},
+		{"merging tokens", Options{Size: 5, Overlap: 2, Count: mergingCount}},
+		{"bytes", Options{Size: 7, Count: func(s string) int { return len(s) }}},
+	}
+	for _, tt := range tests {
+		for name, s := range splitters(tt.opts) {
+			count := tt.opts.withDefaults().Count
+			for i, c := range s.Split(long) {
+				if c.Tokens > tt.opts.Size {
+					t.Errorf("%s/%s: chunk %d %q has %d tokens, want at most %d", tt.name, name, i, c.Text, c.Tokens, tt.opts.Size)
+				}
+				if n := count(c.Text); n != c.Tokens {
+					t.Errorf("%s/%s: chunk %d reports %d tokens, counts %d", tt.name, name, i, c.Tokens, n)
+				}
+			}
+		}
+	}
+}
+
+func TestMarkdownHeadings(t *testing.T) {
+	chunks := NewMarkdown(Options{Size: 512}).Split(sampleMarkdown)
+	var got []string
+	for _, c := range chunks {
+		got = append(got, c.Heading)
+	}
+	want := []string{"Guide", "Guide > Setup", "Guide > Usage"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("headings = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package chunk
+
+import (
+	"strings"
+)
+
+type markdown struct {
+	inner recursive
+}
+
+// NewMarkdown returns a Splitter that first cuts a Markdown document into
+// sections at its headings, so that no chunk spans two sections, and then
+// splits long sections like NewRecursive. Each chunk carries the path of
+// headings it falls under. Lines inside fenced code blocks are never taken
+// for headings.
+func NewMarkdown(opts Options) Splitter {
+	return markdown{inner: recursive{opts: opts.withDefaults(), separators: DefaultSeparators}}
+}
+
+type section struct {
+	start, end int
+	heading    string
+}
+
+func (m markdown) Split(text string) []Chunk {
+	var chunks []Chunk
+	for _, s := range sections(text) {
+		segs := m.inner.segments(text, s.start, s.end, 0)
+		for _, c := range pack(text, segs, m.inner.opts) {
+			c.Heading = s.heading
+			chunks = append(chunks, c)
+		}
+	}
+	return chunks
+}
+
+// sections cuts text before every ATX heading ("# Title") outside of code fences.
+func sections(text string) []section {
+	var (
+		out     []section
+		path    []string // path[i] is the current heading of level i+1
+		start   int
+		heading string
+		fence   string
+	)
+
+	for pos := 0; pos < len(text); {
+		lineEnd := strings.IndexByte(text[pos:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(text)
+		} else {
+			lineEnd += pos + 1
+		}
+		line := strings.TrimRight(text[pos:lineEnd], "\r\n")
+		trimmed := strings.TrimLeft(line, " ")
+
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		default:
+			if level, title, ok := atxHeading(trimmed); ok && len(line)-len(trimmed) < 4 {
+				if pos > start {
+					out = append(out, section{start: start, end: pos, heading: heading})
+				}
+				if level <= len(path) {
+					path = path[:level-1]
+				}
+				for len(path) < level-1 {
+					path = append(path, "")
+				}
+				path = append(path, title)
+				heading = joinHeadings(path)
+				start = pos
+			}
+		}
+		pos = lineEnd
+	}
+	if start < len(text) {
+		out = append(out, section{start: start, end: len(text), heading: heading})
+	}
+	return out
+}
+
+// atxHeading parses a line such as "## Setup ##" into its level and title.
+func atxHeading(line string) (int, string, bool) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0, "", false
+	}
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, "", false
+	}
+	title := strings.TrimSpace(rest)
+	title = strings.TrimSpace(strings.TrimRight(title, "#"))
+	return level, title, true
+}
+
+func joinHeadings(path []string) string {
+	parts := make([]string, 0, len(path))
+	for _, p := range path {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
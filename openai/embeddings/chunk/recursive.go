@@ -0,0 +1,59 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package chunk
+
+import (
+	"strings"
+)
+
+// DefaultSeparators are tried in order by the recursive splitter: paragraphs,
+// lines, sentences, then words.
+var DefaultSeparators = []string{"\n\n", "\n", ". ", "? ", "! ", "; ", " "}
+
+type recursive struct {
+	opts       Options
+	separators []string
+}
+
+// NewRecursive returns a Splitter that keeps paragraphs, then lines, then
+// sentences together where it can. Spans over opts.Size tokens are split on
+// the next separator in turn, down to single words.
+func NewRecursive(opts Options) Splitter {
+	return recursive{opts: opts.withDefaults(), separators: DefaultSeparators}
+}
+
+// NewRecursiveWithSeparators is NewRecursive with custom separators, tried in order.
+func NewRecursiveWithSeparators(opts Options, separators []string) Splitter {
+	return recursive{opts: opts.withDefaults(), separators: separators}
+}
+
+func (r recursive) Split(text string) []Chunk {
+	return pack(text, r.segments(text, 0, len(text), 0), r.opts)
+}
+
+// segments splits src[start:end] on separators[level], recursing into the
+// parts that are still too long. Separators stay attached to the end of the
+// part before them so that offsets cover the whole span.
+func (r recursive) segments(src string, start, end, level int) []segment {
+	n := r.opts.Count(src[start:end])
+	if n <= r.opts.Size {
+		return []segment{{start: start, end: end, tokens: n}}
+	}
+	if level >= len(r.separators) {
+		return words(src, start, end, r.opts)
+	}
+
+	sep := r.separators[level]
+	var segs []segment
+	partStart := start
+	for partStart < end {
+		i := strings.Index(src[partStart:end], sep)
+		partEnd := end
+		if i >= 0 {
+			partEnd = partStart + i + len(sep)
+		}
+		segs = append(segs, r.segments(src, partStart, partEnd, level+1)...)
+		partStart = partEnd
+	}
+	return segs
+}
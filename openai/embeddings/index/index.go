@@ -0,0 +1,307 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+// Package index provides an in-memory vector index for embeddings, with
+// brute-force top-k similarity search and persistence to disk.
+package index
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/AGMETEOR/openai-go/openai"
+	"github.com/AGMETEOR/openai-go/openai/internal/atomicfile"
+)
+
+// Entry is a vector stored in an Index.
+type Entry struct {
+	ID       string
+	Vector   []float32
+	Metadata map[string]string
+}
+
+// Result is an entry returned by Search together with its score.
+type Result struct {
+	Entry
+	Score float32
+}
+
+// Filter selects the entries Search may return by their metadata.
+type Filter func(metadata map[string]string) bool
+
+// MatchMetadata returns a Filter accepting entries whose metadata has every
+// key of want set to the same value.
+func MatchMetadata(want map[string]string) Filter {
+	return func(metadata map[string]string) bool {
+		for k, v := range want {
+			if got, ok := metadata[k]; !ok || got != v {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Index holds vectors of the same dimension and searches them exhaustively.
+// It is safe for concurrent use.
+type Index struct {
+	mu      sync.RWMutex
+	metric  Metric
+	dims    int
+	entries []Entry
+	norms   []float32
+	byID    map[string]int
+}
+
+// New returns an empty Index comparing vectors with metric.
+func New(metric Metric) *Index {
+	return &Index{metric: metric, byID: make(map[string]int)}
+}
+
+// Metric returns the metric the index compares vectors with.
+func (ix *Index) Metric() Metric {
+	return ix.metric
+}
+
+// Len returns the number of entries in the index.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.entries)
+}
+
+// Dims returns the dimension of the vectors in the index, or 0 while it is empty.
+func (ix *Index) Dims() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.dims
+}
+
+// Add stores vector under id, replacing any entry with the same id.
+// All vectors in an index must have the same dimension.
+func (ix *Index) Add(id string, vector []float32, metadata map[string]string) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.add(Entry{ID: id, Vector: vector, Metadata: metadata})
+}
+
+func (ix *Index) add(e Entry) error {
+	if len(e.Vector) == 0 {
+		return fmt.Errorf("index: empty vector for %q", e.ID)
+	}
+	if ix.dims == 0 || len(ix.entries) == 0 {
+		ix.dims = len(e.Vector)
+	}
+	if len(e.Vector) != ix.dims {
+		return fmt.Errorf("index: vector for %q has %d dimensions, want %d", e.ID, len(e.Vector), ix.dims)
+	}
+
+	if i, ok := ix.byID[e.ID]; ok {
+		ix.entries[i] = e
+		ix.norms[i] = Norm(e.Vector)
+		return nil
+	}
+	ix.byID[e.ID] = len(ix.entries)
+	ix.entries = append(ix.entries, e)
+	ix.norms = append(ix.norms, Norm(e.Vector))
+	return nil
+}
+
+// AddResponse stores the embeddings of resp, the i-th input under ids[i].
+// metadata may be nil, or hold one map per id. Nothing is stored unless every
+// embedding can be.
+func (ix *Index) AddResponse(ids []string, resp *openai.EmbeddingResponse, metadata []map[string]string) error {
+	if metadata != nil && len(metadata) != len(ids) {
+		return fmt.Errorf("index: %d metadata entries for %d ids", len(metadata), len(ids))
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	// Check every embedding before adding any, so that a bad response leaves
+	// the index as it was.
+	dims := ix.dims
+	if len(ix.entries) == 0 {
+		dims = 0
+	}
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(ids) {
+			return fmt.Errorf("index: embedding index %d out of range for %d ids", d.Index, len(ids))
+		}
+		if len(d.Embedding) == 0 {
+			return fmt.Errorf("index: empty vector for %q", ids[d.Index])
+		}
+		if dims == 0 {
+			dims = len(d.Embedding)
+		}
+		if len(d.Embedding) != dims {
+			return fmt.Errorf("index: vector for %q has %d dimensions, want %d", ids[d.Index], len(d.Embedding), dims)
+		}
+	}
+
+	for _, d := range resp.Data {
+		e := Entry{ID: ids[d.Index], Vector: d.Embedding}
+		if metadata != nil {
+			e.Metadata = metadata[d.Index]
+		}
+		if err := ix.add(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the entry stored under id.
+func (ix *Index) Get(id string) (Entry, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	i, ok := ix.byID[id]
+	if !ok {
+		return Entry{}, false
+	}
+	return ix.entries[i], true
+}
+
+// Remove deletes the entry stored under id and reports whether there was one.
+func (ix *Index) Remove(id string) bool {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	i, ok := ix.byID[id]
+	if !ok {
+		return false
+	}
+	last := len(ix.entries) - 1
+	if i != last {
+		ix.entries[i] = ix.entries[last]
+		ix.norms[i] = ix.norms[last]
+		ix.byID[ix.entries[i].ID] = i
+	}
+	ix.entries = ix.entries[:last]
+	ix.norms = ix.norms[:last]
+	delete(ix.byID, id)
+	return true
+}
+
+// Search returns the k entries most similar to query, best first.
+// If filter is not nil only entries it accepts are considered.
+func (ix *Index) Search(query []float32, k int, filter Filter) ([]Result, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if k <= 0 || len(ix.entries) == 0 {
+		return nil, nil
+	}
+	if len(query) != ix.dims {
+		return nil, fmt.Errorf("index: query has %d dimensions, want %d", len(query), ix.dims)
+	}
+
+	qNorm := Norm(query)
+	h := make(resultHeap, 0, k)
+	for i, e := range ix.entries {
+		if filter != nil && !filter(e.Metadata) {
+			continue
+		}
+
+		var score float32
+		switch ix.metric {
+		case Cosine:
+			if qNorm != 0 && ix.norms[i] != 0 {
+				score = Dot(query, e.Vector) / (qNorm * ix.norms[i])
+			}
+		default:
+			score = ix.metric.Score(query, e.Vector)
+		}
+
+		if len(h) < k {
+			heap.Push(&h, Result{Entry: e, Score: score})
+		} else if score > h[0].Score {
+			h[0] = Result{Entry: e, Score: score}
+			heap.Fix(&h, 0)
+		}
+	}
+
+	results := make([]Result, len(h))
+	for i := len(h) - 1; i >= 0; i-- {
+		results[i] = heap.Pop(&h).(Result)
+	}
+	return results, nil
+}
+
+// resultHeap is a min-heap on Score holding the best results seen so far.
+type resultHeap []Result
+
+func (h resultHeap) Len() int            { return len(h) }
+func (h resultHeap) Less(i, j int) bool  { return h[i].Score < h[j].Score }
+func (h resultHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap) Push(x interface{}) { *h = append(*h, x.(Result)) }
+func (h *resultHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// snapshotVersion is bumped whenever the on-disk format changes.
+const snapshotVersion = 1
+
+type snapshot struct {
+	Version int
+	Metric  Metric
+	Dims    int
+	Entries []Entry
+}
+
+// ErrUnsupportedVersion is returned by Load for data written by a newer version of this package.
+var ErrUnsupportedVersion = errors.New("index: unsupported snapshot version")
+
+// Save writes the index to w.
+func (ix *Index) Save(w io.Writer) error {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return gob.NewEncoder(w).Encode(snapshot{
+		Version: snapshotVersion,
+		Metric:  ix.metric,
+		Dims:    ix.dims,
+		Entries: ix.entries,
+	})
+}
+
+// Load reads an index written by Save.
+func Load(r io.Reader) (*Index, error) {
+	var s snapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+	if s.Version != snapshotVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, s.Version)
+	}
+
+	ix := New(s.Metric)
+	for _, e := range s.Entries {
+		if err := ix.add(e); err != nil {
+			return nil, err
+		}
+	}
+	return ix, nil
+}
+
+// SaveFile writes the index to path. The file is replaced atomically.
+func (ix *Index) SaveFile(path string) error {
+	return atomicfile.Write(path, ix.Save)
+}
+
+// LoadFile reads an index written by SaveFile.
+func LoadFile(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
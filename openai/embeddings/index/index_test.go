@@ -0,0 +1,216 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package index
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/AGMETEOR/openai-go/openai"
+)
+
+func searchIDs(t *testing.T, ix *Index, query []float32, k int, filter Filter) []string {
+	t.Helper()
+	results, err := ix.Search(query, k, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestIndexAdd(t *testing.T) {
+	ix := New(Cosine)
+	if err := ix.Add("a", []float32{1, 0}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Add("b", []float32{0, 1}, map[string]string{"lang": "go"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Add("c", []float32{1, 0, 0}, nil); err == nil {
+		t.Error("Add with other dimensions succeeded")
+	}
+	if err := ix.Add("c", nil, nil); err == nil {
+		t.Error("Add with an empty vector succeeded")
+	}
+	if ix.Len() != 2 || ix.Dims() != 2 {
+		t.Fatalf("Len() = %d, Dims() = %d, want 2, 2", ix.Len(), ix.Dims())
+	}
+
+	if err := ix.Add("a", []float32{0.5, 0.5}, map[string]string{"lang": "rust"}); err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := ix.Get("a"); !ok || !reflect.DeepEqual(e.Vector, []float32{0.5, 0.5}) || e.Metadata["lang"] != "rust" {
+		t.Errorf("Get(a) = %+v, %v after replacing it", e, ok)
+	}
+	if ix.Len() != 2 {
+		t.Errorf("Len() = %d after replacing an entry, want 2", ix.Len())
+	}
+
+	if !ix.Remove("a") || ix.Remove("a") {
+		t.Error("Remove(a) should succeed once")
+	}
+	if _, ok := ix.Get("a"); ok || ix.Len() != 1 {
+		t.Errorf("a is still in the index, Len() = %d", ix.Len())
+	}
+	if e, ok := ix.Get("b"); !ok || e.Metadata["lang"] != "go" {
+		t.Errorf("Get(b) = %+v, %v after removing a", e, ok)
+	}
+}
+
+func TestIndexAddResponse(t *testing.T) {
+	resp := &openai.EmbeddingResponse{Data: []openai.Embed{
+		{Index: 1, Embedding: []float32{0, 1}},
+		{Index: 0, Embedding: []float32{1, 0}},
+	}}
+	ix := New(DotProduct)
+	err := ix.AddResponse([]string{"first", "second"}, resp, []map[string]string{{"n": "1"}, {"n": "2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := ix.Get("first"); !reflect.DeepEqual(e.Vector, []float32{1, 0}) || e.Metadata["n"] != "1" {
+		t.Errorf("Get(first) = %+v", e)
+	}
+	if e, _ := ix.Get("second"); !reflect.DeepEqual(e.Vector, []float32{0, 1}) || e.Metadata["n"] != "2" {
+		t.Errorf("Get(second) = %+v", e)
+	}
+
+	tests := []struct {
+		name     string
+		ids      []string
+		data     []openai.Embed
+		metadata []map[string]string
+	}{
+		{
+			name: "index out of range",
+			ids:  []string{"x", "y"},
+			data: []openai.Embed{{Index: 0, Embedding: []float32{1, 1}}, {Index: 2, Embedding: []float32{1, 1}}},
+		},
+		{
+			name: "dimensions of the index",
+			ids:  []string{"x", "y"},
+			data: []openai.Embed{{Index: 0, Embedding: []float32{1, 1}}, {Index: 1, Embedding: []float32{1, 1, 1}}},
+		},
+		{
+			name: "empty vector",
+			ids:  []string{"x", "y"},
+			data: []openai.Embed{{Index: 0, Embedding: []float32{1, 1}}, {Index: 1}},
+		},
+		{
+			name:     "metadata count",
+			ids:      []string{"x", "y"},
+			data:     []openai.Embed{{Index: 0, Embedding: []float32{1, 1}}},
+			metadata: []map[string]string{{}},
+		},
+	}
+	for _, tt := range tests {
+		err := ix.AddResponse(tt.ids, &openai.EmbeddingResponse{Data: tt.data}, tt.metadata)
+		if err == nil {
+			t.Errorf("%s: AddResponse succeeded", tt.name)
+		}
+		if _, ok := ix.Get("x"); ok || ix.Len() != 2 {
+			t.Errorf("%s: the index changed: Len() = %d", tt.name, ix.Len())
+		}
+	}
+
+	// An empty index takes the dimensions of the response, which must agree.
+	err = New(Cosine).AddResponse([]string{"x", "y"}, &openai.EmbeddingResponse{Data: []openai.Embed{
+		{Index: 0, Embedding: []float32{1, 1, 1}},
+		{Index: 1, Embedding: []float32{1, 1}},
+	}}, nil)
+	if err == nil {
+		t.Error("AddResponse with mixed dimensions succeeded")
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	vectors := map[string][]float32{
+		"east":      {1, 0},
+		"north":     {0, 1},
+		"northeast": {0.7, 0.7},
+		"far-east":  {10, 0},
+		"west":      {-1, 0},
+	}
+	newIndex := func(metric Metric) *Index {
+		ix := New(metric)
+		for id, v := range vectors {
+			lang := "en"
+			if id == "east" || id == "west" {
+				lang = "fr"
+			}
+			if err := ix.Add(id, v, map[string]string{"lang": lang}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return ix
+	}
+
+	tests := []struct {
+		name   string
+		metric Metric
+		query  []float32
+		k      int
+		filter Filter
+		want   []string
+	}{
+		{"cosine", Cosine, []float32{0.1, 1}, 2, nil, []string{"north", "northeast"}},
+		{"dot product", DotProduct, []float32{1, 0.5}, 3, nil, []string{"far-east", "northeast", "east"}},
+		{"l2", L2, []float32{0.9, 0.1}, 2, nil, []string{"east", "northeast"}},
+		{"k over Len", L2, []float32{0.1, 1}, 10, nil, []string{"north", "northeast", "east", "west", "far-east"}},
+		{"filter", Cosine, []float32{1, 0}, 5, MatchMetadata(map[string]string{"lang": "fr"}), []string{"east", "west"}},
+		{"zero k", Cosine, []float32{1, 0}, 0, nil, []string{}},
+	}
+	for _, tt := range tests {
+		ix := newIndex(tt.metric)
+		if got := searchIDs(t, ix, tt.query, tt.k, tt.filter); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := newIndex(Cosine).Search([]float32{1, 0, 0}, 1, nil); err == nil {
+		t.Error("Search with other dimensions succeeded")
+	}
+}
+
+func TestIndexSaveLoad(t *testing.T) {
+	ix := New(L2)
+	ix.Add("a", []float32{1, 2, 3}, map[string]string{"src": "doc.md"})
+	ix.Add("b", []float32{4, 5, 6}, nil)
+	ix.Add("c", []float32{7, 8, 9}, nil)
+	ix.Remove("b")
+
+	path := filepath.Join(t.TempDir(), "index.gob")
+	if err := ix.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Metric() != L2 || loaded.Dims() != 3 || loaded.Len() != 2 {
+		t.Fatalf("loaded %v index with %d dims and %d entries", loaded.Metric(), loaded.Dims(), loaded.Len())
+	}
+	for _, id := range []string{"a", "c"} {
+		want, _ := ix.Get(id)
+		if got, ok := loaded.Get(id); !ok || !reflect.DeepEqual(got, want) {
+			t.Errorf("Get(%s) = %+v, want %+v", id, got, want)
+		}
+	}
+	query := []float32{6, 7, 8}
+	if got, want := searchIDs(t, loaded, query, 2, nil), searchIDs(t, ix, query, 2, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded index finds %v, want %v", got, want)
+	}
+
+	var buf bytes.Buffer
+	gob.NewEncoder(&buf).Encode(snapshot{Version: snapshotVersion + 1})
+	if _, err := Load(&buf); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Load of a newer snapshot: err = %v, want ErrUnsupportedVersion", err)
+	}
+}
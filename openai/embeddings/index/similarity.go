@@ -0,0 +1,92 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package index
+
+import (
+	"fmt"
+	"math"
+)
+
+// Metric is the measure used to compare vectors.
+type Metric int
+
+const (
+	// Cosine compares the angle between vectors. OpenAI embeddings are
+	// normalized, so it ranks the same as DotProduct.
+	Cosine Metric = iota
+	// DotProduct is the inner product of the vectors.
+	DotProduct
+	// L2 is the Euclidean distance between the vectors.
+	L2
+)
+
+func (m Metric) String() string {
+	switch m {
+	case Cosine:
+		return "cosine"
+	case DotProduct:
+		return "dot"
+	case L2:
+		return "l2"
+	}
+	return fmt.Sprintf("Metric(%d)", int(m))
+}
+
+// Score returns how similar a and b are under m; higher is more similar.
+// For L2 that is the negated distance.
+func (m Metric) Score(a, b []float32) float32 {
+	switch m {
+	case DotProduct:
+		return Dot(a, b)
+	case L2:
+		return -L2Distance(a, b)
+	default:
+		return CosineSimilarity(a, b)
+	}
+}
+
+// Dot returns the inner product of a and b, which must have the same length.
+func Dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// Norm returns the Euclidean length of v.
+func Norm(v []float32) float32 {
+	return float32(math.Sqrt(float64(Dot(v, v))))
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b,
+// or 0 if either is the zero vector.
+func CosineSimilarity(a, b []float32) float32 {
+	na, nb := Norm(a), Norm(b)
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return Dot(a, b) / (na * nb)
+}
+
+// L2Distance returns the Euclidean distance between a and b.
+func L2Distance(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return float32(math.Sqrt(float64(sum)))
+}
+
+// Normalize scales v in place to unit length and returns it.
+func Normalize(v []float32) []float32 {
+	n := Norm(v)
+	if n == 0 {
+		return v
+	}
+	for i := range v {
+		v[i] /= n
+	}
+	return v
+}
@@ -0,0 +1,195 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/AGMETEOR/openai-go/openai/tokenizer"
+)
+
+// Limits of a single embeddings request.
+const (
+	MaxEmbeddingInputsPerRequest = 2048
+	MaxEmbeddingTokensPerRequest = 300000
+)
+
+// EmbedAllOptions configures EmbedAll. The zero value is usable.
+type EmbedAllOptions struct {
+	// MaxInputsPerRequest caps the number of inputs sent in one request.
+	// Defaults to MaxEmbeddingInputsPerRequest.
+	MaxInputsPerRequest int
+
+	// MaxTokensPerRequest caps the tokens of all inputs sent in one request.
+	// Defaults to MaxEmbeddingTokensPerRequest. Tokens are estimated when the
+	// tokenizer does not know the model, such as a custom deployment name.
+	MaxTokensPerRequest int
+
+	// Concurrency is the number of requests in flight at once. Defaults to 4.
+	Concurrency int
+
+	// MaxRetries is how often a failed batch is retried when the error is
+	// temporary. Defaults to 3; a negative value disables retries.
+	MaxRetries int
+
+	// Dimensions and User are passed on to every request.
+	Dimensions int
+	User       string
+
+	// Progress, when set, is called after each batch completes with the number
+	// of inputs embedded so far and the total. Calls are serialized.
+	Progress func(done, total int)
+}
+
+// checkEmbeddingIndexes verifies that data holds exactly one vector for each
+// of the n inputs of a request.
+func checkEmbeddingIndexes(data []Embed, n int) error {
+	if len(data) != n {
+		return fmt.Errorf("got %d vectors for %d inputs", len(data), n)
+	}
+	seen := make([]bool, n)
+	for _, d := range data {
+		if d.Index < 0 || d.Index >= n || seen[d.Index] {
+			return fmt.Errorf("got an unexpected vector index %d for %d inputs", d.Index, n)
+		}
+		seen[d.Index] = true
+	}
+	return nil
+}
+
+// embeddingBatch is a contiguous range of the inputs sent in one request.
+type embeddingBatch struct {
+	start, end int
+}
+
+// EmbedAll embeds every input with model, splitting them into requests that
+// respect the per-request input and token limits and sending those
+// concurrently. The vectors are returned in the order of inputs.
+// Requests ask for base64 encoded embeddings to reduce transfer size.
+func (em *EmbeddingsAPI) EmbedAll(ctx context.Context, model string, inputs []string, opts *EmbedAllOptions) ([][]float32, error) {
+	if opts == nil {
+		opts = &EmbedAllOptions{}
+	}
+	maxInputs := opts.MaxInputsPerRequest
+	if maxInputs <= 0 || maxInputs > MaxEmbeddingInputsPerRequest {
+		maxInputs = MaxEmbeddingInputsPerRequest
+	}
+	maxTokens := opts.MaxTokensPerRequest
+	if maxTokens <= 0 {
+		maxTokens = MaxEmbeddingTokensPerRequest
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = 3
+	}
+
+	batches := splitEmbeddingBatches(inputs, tokenizer.CounterForModelOrEstimate(model), maxInputs, maxTokens)
+	vectors := make([][]float32, len(inputs))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		done     int
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, concurrency)
+	)
+
+	for _, b := range batches {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(b embeddingBatch) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			embReq := &EmbeddingRequest{
+				Model:          model,
+				Input:          EmbeddingTexts(inputs[b.start:b.end]),
+				EncodingFormat: EmbeddingEncodingBase64,
+				Dimensions:     opts.Dimensions,
+				User:           opts.User,
+			}
+			embResp, err := em.createWithRetry(ctx, embReq, maxRetries)
+			if err == nil {
+				err = checkEmbeddingIndexes(embResp.Data, b.end-b.start)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("openai: embedding inputs %d-%d: %w", b.start, b.end-1, err)
+					cancel()
+				}
+				return
+			}
+			for _, d := range embResp.Data {
+				vectors[b.start+d.Index] = d.Embedding
+			}
+			done += b.end - b.start
+			if opts.Progress != nil && firstErr == nil {
+				opts.Progress(done, len(inputs))
+			}
+		}(b)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// createWithRetry sends embReq, retrying temporary failures with backoff.
+func (em *EmbeddingsAPI) createWithRetry(ctx context.Context, embReq *EmbeddingRequest, maxRetries int) (*EmbeddingResponse, error) {
+	for attempt := 0; ; attempt++ {
+		embResp, _, err := em.CreateEmbeddings(ctx, embReq)
+		if err == nil {
+			return embResp, nil
+		}
+		if attempt >= maxRetries || !isRetryable(err) {
+			return nil, err
+		}
+		if err := sleepCtx(ctx, retryDelay(attempt, err)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// splitEmbeddingBatches groups consecutive inputs into batches of at most
+// maxInputs inputs and maxTokens tokens. An input over maxTokens on its own
+// gets a batch to itself and is left for the API to reject.
+func splitEmbeddingBatches(inputs []string, count func(string) int, maxInputs, maxTokens int) []embeddingBatch {
+	var batches []embeddingBatch
+	start, tokens := 0, 0
+	for i, in := range inputs {
+		n := count(in)
+		if i > start && (i-start >= maxInputs || tokens+n > maxTokens) {
+			batches = append(batches, embeddingBatch{start: start, end: i})
+			start, tokens = i, 0
+		}
+		tokens += n
+	}
+	if start < len(inputs) {
+		batches = append(batches, embeddingBatch{start: start, end: len(inputs)})
+	}
+	return batches
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// embeddingServer answers each input of a request with a one dimensional
+// vector holding the number parsed from the input. shift moves the index of
+// every returned vector.
+func embeddingServer(t *testing.T, shift int) *OpenAIClient {
+	t.Helper()
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		var data []string
+		for i, in := range req.Input {
+			n, _ := strconv.Atoi(strings.TrimPrefix(in, "input "))
+			data = append(data, fmt.Sprintf(`{"object":"embedding","index":%d,"embedding":[%d]}`, i+shift, n))
+		}
+		fmt.Fprintf(w, `{"object":"list","data":[%s]}`, strings.Join(data, ","))
+	}))
+}
+
+func TestEmbedAllKeepsInputOrder(t *testing.T) {
+	c := embeddingServer(t, 0)
+	inputs := make([]string, 25)
+	for i := range inputs {
+		inputs[i] = "input " + strconv.Itoa(i)
+	}
+	var progress []int
+	vectors, err := c.Embeddings.EmbedAll(context.Background(), "text-embedding-3-small", inputs, &EmbedAllOptions{
+		MaxInputsPerRequest: 4,
+		Concurrency:         3,
+		Progress:            func(done, total int) { progress = append(progress, done) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range vectors {
+		if len(v) != 1 || int(v[0]) != i {
+			t.Fatalf("vector %d = %v, want [%d]", i, v, i)
+		}
+	}
+	if len(progress) != 7 || progress[len(progress)-1] != len(inputs) {
+		t.Errorf("progress = %v, want 7 calls ending at %d", progress, len(inputs))
+	}
+}
+
+func TestEmbedAllRejectsOutOfRangeIndex(t *testing.T) {
+	c := embeddingServer(t, 1)
+	_, err := c.Embeddings.EmbedAll(context.Background(), "text-embedding-3-small", []string{"input 0", "input 1"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "unexpected vector index 2") {
+		t.Errorf("err = %v, want an unexpected vector index error", err)
+	}
+}
+
+func TestCheckEmbeddingIndexes(t *testing.T) {
+	tests := []struct {
+		indexes []int
+		n       int
+		ok      bool
+	}{
+		{[]int{0, 1, 2}, 3, true},
+		{[]int{2, 0, 1}, 3, true},
+		{[]int{0, 1}, 3, false},
+		{[]int{0, 0, 1}, 3, false},
+		{[]int{0, 1, -1}, 3, false},
+		{[]int{0, 1, 3}, 3, false},
+	}
+	for _, tt := range tests {
+		data := make([]Embed, len(tt.indexes))
+		for i, idx := range tt.indexes {
+			data[i].Index = idx
+		}
+		if err := checkEmbeddingIndexes(data, tt.n); (err == nil) != tt.ok {
+			t.Errorf("checkEmbeddingIndexes(%v, %d) = %v, want ok %v", tt.indexes, tt.n, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned when downloaded content does not match
+// FileDownloadOptions.Checksum.
+var ErrChecksumMismatch = errors.New("openai: file checksum mismatch")
+
+// FileDownloadOptions configures DownloadFileContent and OpenFileContent.
+// The zero value downloads the whole file without verification.
+type FileDownloadOptions struct {
+	// Offset resumes the download at this byte with an HTTP Range request.
+	Offset int64
+
+	// Checksum, when set, is the hex encoded digest of the whole file. The
+	// content is checked against it once fully read.
+	Checksum string
+
+	// Hash computes the digest compared to Checksum. It defaults to SHA-256.
+	// When resuming with Checksum set, Hash must already have been fed the
+	// first Offset bytes of the file.
+	Hash hash.Hash
+
+	// MaxRetries is how many times a dropped connection is resumed from the
+	// last byte received. Defaults to 3; a negative value disables resuming.
+	MaxRetries int
+}
+
+// OpenFileContent returns the contents of the specified file as a stream the
+// caller must close. Dropped connections are resumed transparently and, when
+// opts.Checksum is set, the final Read returns ErrChecksumMismatch instead of
+// io.EOF if the content does not match.
+func (f *FileAPI) OpenFileContent(ctx context.Context, id string, opts *FileDownloadOptions) (io.ReadCloser, *Response, error) {
+	if opts == nil {
+		opts = &FileDownloadOptions{}
+	}
+	r := &fileContentReader{
+		ctx:     ctx,
+		files:   f,
+		id:      id,
+		offset:  opts.Offset,
+		hash:    opts.Hash,
+		retries: opts.MaxRetries,
+	}
+	if opts.Checksum != "" {
+		sum, err := hex.DecodeString(strings.TrimSpace(opts.Checksum))
+		if err != nil {
+			return nil, nil, fmt.Errorf("openai: invalid checksum %q: %w", opts.Checksum, err)
+		}
+		if r.hash == nil {
+			if opts.Offset > 0 {
+				return nil, nil, errors.New("openai: resuming a verified download needs a Hash fed with the first Offset bytes")
+			}
+			r.hash = sha256.New()
+		}
+		r.checksum = sum
+	}
+	if r.retries == 0 {
+		r.retries = 3
+	}
+
+	resp, err := r.open()
+	if err != nil {
+		return nil, resp, err
+	}
+	return r, resp, nil
+}
+
+// DownloadFileContent streams the contents of the specified file to w,
+// resuming dropped connections and verifying opts.Checksum if set. It returns
+// the number of bytes written.
+func (f *FileAPI) DownloadFileContent(ctx context.Context, id string, w io.Writer, opts *FileDownloadOptions) (int64, *Response, error) {
+	rc, resp, err := f.OpenFileContent(ctx, id, opts)
+	if err != nil {
+		return 0, resp, err
+	}
+	defer rc.Close()
+
+	n, err := io.Copy(w, rc)
+	return n, resp, err
+}
+
+// DownloadFileToPath downloads the specified file to path. If path already
+// holds the beginning of the file, for instance from an interrupted earlier
+// call, the download resumes after it. A failed download leaves what was
+// written in place so a later call can resume it, and a checksum mismatch
+// leaves the file for the caller to inspect or remove. Only a file this call
+// created and wrote nothing to is removed on failure.
+func (f *FileAPI) DownloadFileToPath(ctx context.Context, id, path, checksum string) (*Response, error) {
+	created := true
+	out, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		created = false
+		out, err = os.OpenFile(path, os.O_RDWR, 0o644)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	n, resp, err := f.downloadTo(ctx, id, out, checksum)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil && created && n == 0 {
+		os.Remove(path)
+	}
+	return resp, err
+}
+
+// downloadTo downloads the specified file into out, resuming after the
+// contents out already holds. It returns the number of bytes written.
+func (f *FileAPI) downloadTo(ctx context.Context, id string, out *os.File, checksum string) (int64, *Response, error) {
+	opts := &FileDownloadOptions{Checksum: checksum}
+	if checksum != "" {
+		opts.Hash = sha256.New()
+	}
+	// Re-reading the partial file seeds the hash and finds the resume offset.
+	var (
+		seed io.Writer = io.Discard
+		err  error
+	)
+	if opts.Hash != nil {
+		seed = opts.Hash
+	}
+	if opts.Offset, err = io.Copy(seed, out); err != nil {
+		return 0, nil, err
+	}
+
+	n, resp, err := f.DownloadFileContent(ctx, id, out, opts)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode() == http.StatusRequestedRangeNotSatisfiable && opts.Offset > 0 {
+		// The partial file was complete already; only the checksum is left to check.
+		if opts.Hash != nil && hex.EncodeToString(opts.Hash.Sum(nil)) != strings.ToLower(strings.TrimSpace(checksum)) {
+			return n, resp, ErrChecksumMismatch
+		}
+		return n, resp, nil
+	}
+	return n, resp, err
+}
+
+// fileContentReader reads a file's content, reconnecting with a Range request
+// after a dropped connection.
+type fileContentReader struct {
+	ctx      context.Context
+	files    *FileAPI
+	id       string
+	body     io.ReadCloser
+	offset   int64
+	hash     hash.Hash
+	checksum []byte
+	retries  int
+	attempt  int
+	closed   bool
+}
+
+// open requests the content from r.offset on.
+func (r *fileContentReader) open() (*Response, error) {
+	u := fmt.Sprintf("v1/files/%s/content", r.id)
+	req, err := r.files.openAIClient.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Del("Content-Type")
+	if r.offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.offset))
+	}
+
+	resp, err := r.files.openAIClient.send(r.ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	response := newResponse(resp)
+	if err := CheckResponse(resp); err != nil {
+		resp.Body.Close()
+		return response, err
+	}
+
+	// A server that ignores Range sends the whole file; skip what we have.
+	if r.offset > 0 && resp.StatusCode != http.StatusPartialContent {
+		if _, err := io.CopyN(io.Discard, resp.Body, r.offset); err != nil {
+			resp.Body.Close()
+			return response, err
+		}
+	}
+	r.body = resp.Body
+	return response, nil
+}
+
+func (r *fileContentReader) Read(p []byte) (int, error) {
+	if r.closed {
+		return 0, errors.New("openai: read from closed file content")
+	}
+	for {
+		if r.body == nil {
+			if _, err := r.open(); err != nil {
+				return 0, err
+			}
+		}
+
+		n, err := r.body.Read(p)
+		if n > 0 {
+			r.offset += int64(n)
+			if r.hash != nil {
+				r.hash.Write(p[:n])
+			}
+		}
+		switch {
+		case err == nil:
+			return n, nil
+		case err == io.EOF:
+			if r.checksum != nil && !bytes.Equal(r.hash.Sum(nil), r.checksum) {
+				return n, ErrChecksumMismatch
+			}
+			return n, io.EOF
+		}
+
+		// The connection dropped: resume from r.offset on the next Read.
+		r.body.Close()
+		r.body = nil
+		if ctxErr := r.ctx.Err(); ctxErr != nil {
+			return n, ctxErr
+		}
+		if r.retries < 0 || r.attempt >= r.retries {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+		if serr := sleepCtx(r.ctx, retryDelay(r.attempt, err)); serr != nil {
+			return 0, serr
+		}
+		r.attempt++
+	}
+}
+
+func (r *fileContentReader) Close() error {
+	r.closed = true
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
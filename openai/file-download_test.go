@@ -0,0 +1,134 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const downloadContent = `{"prompt":"a","completion":"b"}`
+
+// downloadServer serves downloadContent as file-1, honoring Range requests.
+// file-stall sends the first half of it and then stalls until the request is
+// cancelled. Every other file is missing.
+func downloadServer(t *testing.T) *OpenAIClient {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/files/file-1/content", func(w http.ResponseWriter, r *http.Request) {
+		var offset int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &offset); err == nil {
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		fmt.Fprint(w, downloadContent[offset:])
+	})
+	mux.HandleFunc("GET /v1/files/file-stall/content", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(downloadContent)))
+		fmt.Fprint(w, downloadContent[:len(downloadContent)/2])
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	mux.HandleFunc("GET /v1/files/{id}/content", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, "No such File object: "+r.PathValue("id"))
+	})
+	return newTestClient(t, mux)
+}
+
+func downloadChecksum() string {
+	sum := sha256.Sum256([]byte(downloadContent))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDownloadFileToPath(t *testing.T) {
+	c := downloadServer(t)
+	path := filepath.Join(t.TempDir(), "ok.jsonl")
+	if _, err := c.File.DownloadFileToPath(context.Background(), "file-1", path, downloadChecksum()); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(path); err != nil || string(b) != downloadContent {
+		t.Errorf("file holds %q, %v, want %q", b, err, downloadContent)
+	}
+}
+
+func TestDownloadFileToPathResumes(t *testing.T) {
+	c := downloadServer(t)
+	path := filepath.Join(t.TempDir(), "partial.jsonl")
+
+	// A cancelled download keeps what it wrote.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.File.DownloadFileToPath(ctx, "file-stall", path, "")
+		done <- err
+	}()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if fi, err := os.Stat(path); err == nil && fi.Size() > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("download wrote nothing")
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	half := downloadContent[:len(downloadContent)/2]
+	if b, _ := os.ReadFile(path); string(b) != half {
+		t.Fatalf("cancelled download left %q, want %q", b, half)
+	}
+
+	// The next call fetches the rest and verifies the whole file.
+	if _, err := c.File.DownloadFileToPath(context.Background(), "file-1", path, downloadChecksum()); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != downloadContent {
+		t.Errorf("resumed file holds %q, want %q", b, downloadContent)
+	}
+}
+
+func TestDownloadFileToPathFailures(t *testing.T) {
+	c := downloadServer(t)
+	dir := t.TempDir()
+
+	// A file the call created and wrote nothing to is removed.
+	path := filepath.Join(dir, "gone.jsonl")
+	_, err := c.File.DownloadFileToPath(context.Background(), "file-gone", path, "")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode() != http.StatusNotFound {
+		t.Errorf("err = %v, want a 404", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("failed download left %s behind: %v", path, err)
+	}
+
+	// A file that was there before is kept.
+	path = filepath.Join(dir, "existing.jsonl")
+	if err := os.WriteFile(path, []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.File.DownloadFileToPath(context.Background(), "file-gone", path, ""); err == nil {
+		t.Error("download of a missing file succeeded")
+	}
+	if b, err := os.ReadFile(path); err != nil || string(b) != "partial" {
+		t.Errorf("existing file holds %q, %v, want it untouched", b, err)
+	}
+
+	path = filepath.Join(dir, "mismatch.jsonl")
+	_, err = c.File.DownloadFileToPath(context.Background(), "file-1", path, strings.Repeat("0", 2*sha256.Size))
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("err = %v, want ErrChecksumMismatch", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("checksum mismatch removed the file: %v", err)
+	}
+}
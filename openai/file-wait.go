@@ -0,0 +1,57 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// FileProcessingError is returned by WaitForFileProcessed when a file fails
+// the validation for its purpose, such as a malformed fine-tuning dataset.
+type FileProcessingError struct {
+	ID      string
+	Purpose string
+	// Details is the file's status_details, explaining what was wrong.
+	Details string
+}
+
+func (e *FileProcessingError) Error() string {
+	if e.Details == "" {
+		return fmt.Sprintf("openai: file %s failed processing for %s", e.ID, e.Purpose)
+	}
+	return fmt.Sprintf("openai: file %s failed processing for %s: %s", e.ID, e.Purpose, e.Details)
+}
+
+// WaitForFileProcessed polls the file id until it is processed and returns it.
+// A file that fails processing returns the file together with a
+// *FileProcessingError.
+func (f *FileAPI) WaitForFileProcessed(ctx context.Context, id string) (*File, error) {
+	const maxDelay = 10 * time.Second
+	delay := 500 * time.Millisecond
+	for attempt := 0; ; {
+		file, _, err := f.RetrieveFile(ctx, id)
+		wait := delay
+		switch {
+		case err != nil && !isRetryable(err):
+			return nil, err
+		case err != nil:
+			wait = retryDelay(attempt, err)
+			attempt++
+		case file.Status == FileStatusError:
+			return file, &FileProcessingError{ID: id, Purpose: file.Purpose, Details: file.StatusDetails}
+		case file.Status == FileStatusProcessed, file.Status == "":
+			// Files from before the status field was reported are ready.
+			return file, nil
+		default:
+			if delay *= 2; delay > maxDelay {
+				delay = maxDelay
+			}
+		}
+
+		if err := sleepCtx(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -5,11 +5,32 @@ package openai
 import (
 	"context"
 	"fmt"
+	"io"
+	"iter"
 	"net/http"
+	"os"
+	"path/filepath"
 )
 
 type FileAPI Api
 
+// Purposes a file can be uploaded with.
+const (
+	FilePurposeAssistants = "assistants"
+	FilePurposeBatch      = "batch"
+	FilePurposeFineTune   = "fine-tune"
+	FilePurposeVision     = "vision"
+	FilePurposeUserData   = "user_data"
+	FilePurposeEvals      = "evals"
+)
+
+// Statuses of an uploaded file.
+const (
+	FileStatusUploaded  = "uploaded"
+	FileStatusProcessed = "processed"
+	FileStatusError     = "error"
+)
+
 type File struct {
 	ID        string `json:"id"`
 	Object    string `json:"object"`
@@ -17,11 +38,19 @@ type File struct {
 	CreatedAt int64  `json:"created_at"`
 	Filename  string `json:"filename"`
 	Purpose   string `json:"purpose"`
+
+	// Status is one of the FileStatus constants. StatusDetails explains why
+	// a file failed validation for its purpose.
+	Status        string `json:"status"`
+	StatusDetails string `json:"status_details"`
 }
 
 type FileList struct {
-	Data   []File `json:"data"`
-	Object string `json:"object"`
+	Data    []File `json:"data"`
+	Object  string `json:"object"`
+	HasMore bool   `json:"has_more"`
+	FirstID string `json:"first_id"`
+	LastID  string `json:"last_id"`
 }
 
 type FileDeleteResponse struct {
@@ -31,19 +60,24 @@ type FileDeleteResponse struct {
 }
 
 type FileUploadRequest struct {
-	// Name of the JSON Lines file to be uploaded.
+	// Path of the file to be uploaded, or only its name when Reader is set.
 	// If the purpose is set to "fine-tune",
 	// each line is a JSON record with "prompt" and "completion" fields representing your training examples (https://platform.openai.com/docs/guides/fine-tuning/prepare-training-data).
 	File string `json:"file"`
 
+	// Reader, when set, supplies the content of the file instead of reading it
+	// from File, which must then still name the file.
+	Reader io.Reader `json:"-"`
+
 	// The intended purpose of the uploaded documents.
 	// Use "fine-tune" for Fine-tuning. This allows us to validate the format of the uploaded file.
 	Purpose string `json:"purpose"`
 }
 
 // List returns a list of files that belong to the user's organization.
-func (f *FileAPI) List(ctx context.Context) (*FileList, *Response, error) {
-	u := "v1/files"
+// Passing opts returns one page of it.
+func (f *FileAPI) List(ctx context.Context, opts ...*ListOptions) (*FileList, *Response, error) {
+	u := firstListOptions(opts).encode("v1/files")
 	req, err := f.openAIClient.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, nil, err
@@ -59,12 +93,40 @@ func (f *FileAPI) List(ctx context.Context) (*FileList, *Response, error) {
 	return fLResp, resp, nil
 }
 
+// ListAll iterates over all files that belong to the user's organization,
+// fetching pages as needed.
+func (f *FileAPI) ListAll(ctx context.Context, opts *ListOptions) iter.Seq2[File, error] {
+	return paginate(ctx, opts, func(ctx context.Context, o *ListOptions) (page[File], error) {
+		l, _, err := f.List(ctx, o)
+		if err != nil {
+			return page[File]{}, err
+		}
+		return page[File]{items: l.Data, hasMore: l.HasMore, lastID: l.LastID}, nil
+	}, func(file File) string { return file.ID })
+}
+
 // UploadFile uploads a file that contains document(s) to be used across various endpoints/features.
 // Currently, the size of all the files uploaded by one organization can be up to 1 GB.
 // Please contact https://help.openai.com/ if you need to increase the storage limit.
 func (f *FileAPI) UploadFile(ctx context.Context, fuReq *FileUploadRequest) (*File, *Response, error) {
+	name := filepath.Base(fuReq.File)
+	if name == "." || name == string(filepath.Separator) {
+		return nil, nil, fmt.Errorf("openai: upload needs a file name, got %q", fuReq.File)
+	}
+
+	content := fuReq.Reader
+	if content == nil {
+		file, err := os.Open(fuReq.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer file.Close()
+		content = file
+	}
+
 	u := "v1/files"
-	req, err := f.openAIClient.NewRequest(http.MethodPost, u, fuReq)
+	fields := map[string]string{"purpose": fuReq.Purpose}
+	req, err := f.openAIClient.newMultipartRequest(u, fields, "file", name, content)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -115,7 +177,10 @@ func (f *FileAPI) RetrieveFile(ctx context.Context, id string) (*File, *Response
 	return fResp, resp, nil
 }
 
-// RetrieveFileContent returns the contents of the specified file.
+// RetrieveFileContent requests the contents of the specified file.
+//
+// Deprecated: The content is discarded before it can be read. Use
+// DownloadFileContent or OpenFileContent.
 func (f *FileAPI) RetrieveFileContent(ctx context.Context, id string) (*Response, error) {
 	u := fmt.Sprintf("v1/files/%s/content", id)
 	req, err := f.openAIClient.NewRequest(http.MethodGet, u, nil)
@@ -0,0 +1,72 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// uploadedFile is what the fake files endpoint received.
+type uploadedFile struct {
+	name, purpose, content string
+}
+
+func fileUploadServer(t *testing.T, uploads *[]uploadedFile) *OpenAIClient {
+	t.Helper()
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("reading the multipart body: %v", err)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		*uploads = append(*uploads, uploadedFile{header.Filename, r.FormValue("purpose"), string(content)})
+		fmt.Fprintf(w, `{"id":"file-%d","filename":%q,"purpose":%q}`, len(*uploads), header.Filename, r.FormValue("purpose"))
+	}))
+}
+
+func TestUploadFile(t *testing.T) {
+	var uploads []uploadedFile
+	c := fileUploadServer(t, &uploads)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "train.jsonl")
+	if err := os.WriteFile(path, []byte("from disk\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.File.UploadFile(ctx, &FileUploadRequest{File: path, Purpose: FilePurposeFineTune}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.File.UploadFile(ctx, &FileUploadRequest{File: "batch.jsonl", Reader: strings.NewReader("from memory\n"), Purpose: FilePurposeBatch}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []uploadedFile{
+		{"train.jsonl", FilePurposeFineTune, "from disk\n"},
+		{"batch.jsonl", FilePurposeBatch, "from memory\n"},
+	}
+	if fmt.Sprint(uploads) != fmt.Sprint(want) {
+		t.Errorf("server received %+v, want %+v", uploads, want)
+	}
+}
+
+func TestUploadFileNeedsName(t *testing.T) {
+	var uploads []uploadedFile
+	c := fileUploadServer(t, &uploads)
+	for _, name := range []string{"", "/"} {
+		_, _, err := c.File.UploadFile(context.Background(), &FileUploadRequest{File: name, Reader: strings.NewReader("x"), Purpose: FilePurposeBatch})
+		if err == nil {
+			t.Errorf("upload named %q succeeded", name)
+		}
+	}
+	if len(uploads) != 0 {
+		t.Errorf("unnamed uploads reached the server: %+v", uploads)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrNoResultFiles is returned when metrics are requested for a job that has
+// not produced a results file yet.
+var ErrNoResultFiles = errors.New("openai: fine-tune has no result files")
+
+// MetricPoint is the value of a metric at a training step.
+type MetricPoint struct {
+	Step  int
+	Value float64
+}
+
+// FineTuneMetrics holds the per-step series of a fine-tune's results file.
+// Validation metrics are only computed at some steps, so their series are
+// usually shorter than the training ones.
+type FineTuneMetrics struct {
+	TrainingLoss       []MetricPoint
+	TrainingAccuracy   []MetricPoint
+	ValidationLoss     []MetricPoint
+	ValidationAccuracy []MetricPoint
+
+	// Other holds the remaining numeric columns, such as elapsed_tokens or
+	// the classification metrics, keyed by column name.
+	Other map[string][]MetricPoint
+}
+
+// metricColumns maps the column names used by the legacy fine-tunes and the
+// fine-tuning jobs results files to the series they fill.
+var metricColumns = map[string]func(m *FineTuneMetrics) *[]MetricPoint{
+	"training_loss":             func(m *FineTuneMetrics) *[]MetricPoint { return &m.TrainingLoss },
+	"train_loss":                func(m *FineTuneMetrics) *[]MetricPoint { return &m.TrainingLoss },
+	"training_token_accuracy":   func(m *FineTuneMetrics) *[]MetricPoint { return &m.TrainingAccuracy },
+	"train_accuracy":            func(m *FineTuneMetrics) *[]MetricPoint { return &m.TrainingAccuracy },
+	"train_mean_token_accuracy": func(m *FineTuneMetrics) *[]MetricPoint { return &m.TrainingAccuracy },
+	"validation_loss":           func(m *FineTuneMetrics) *[]MetricPoint { return &m.ValidationLoss },
+	"valid_loss":                func(m *FineTuneMetrics) *[]MetricPoint { return &m.ValidationLoss },
+	"validation_token_accuracy": func(m *FineTuneMetrics) *[]MetricPoint { return &m.ValidationAccuracy },
+	"valid_accuracy":            func(m *FineTuneMetrics) *[]MetricPoint { return &m.ValidationAccuracy },
+	"valid_mean_token_accuracy": func(m *FineTuneMetrics) *[]MetricPoint { return &m.ValidationAccuracy },
+}
+
+// ParseFineTuneResults parses a fine-tune results CSV. Empty cells are skipped.
+func ParseFineTuneResults(r io.Reader) (*FineTuneMetrics, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("openai: reading results header: %w", err)
+	}
+	stepCol := -1
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+		if header[i] == "step" {
+			stepCol = i
+		}
+	}
+	if stepCol < 0 {
+		return nil, errors.New(`openai: results file has no "step" column`)
+	}
+
+	m := &FineTuneMetrics{Other: make(map[string][]MetricPoint)}
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if stepCol >= len(record) {
+			return nil, fmt.Errorf("openai: results line %d has no step", line)
+		}
+		step, err := strconv.Atoi(strings.TrimSpace(record[stepCol]))
+		if err != nil {
+			return nil, fmt.Errorf("openai: results line %d: invalid step %q", line, record[stepCol])
+		}
+
+		for i, cell := range record {
+			cell = strings.TrimSpace(cell)
+			if i == stepCol || i >= len(header) || cell == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				continue // not a numeric column
+			}
+			point := MetricPoint{Step: step, Value: value}
+			if series, ok := metricColumns[header[i]]; ok {
+				s := series(m)
+				*s = append(*s, point)
+			} else {
+				m.Other[header[i]] = append(m.Other[header[i]], point)
+			}
+		}
+	}
+	return m, nil
+}
+
+// resultMetrics downloads and parses the results file fileID.
+func (c *OpenAIClient) resultMetrics(ctx context.Context, fileID string) (*FineTuneMetrics, error) {
+	var buf bytes.Buffer
+	if _, _, err := c.File.DownloadFileContent(ctx, fileID, &buf, nil); err != nil {
+		return nil, err
+	}
+	// Fine-tuning jobs serve their results file base64 encoded.
+	data := bytes.TrimSpace(buf.Bytes())
+	if !bytes.Contains(data, []byte(",")) {
+		if decoded, err := base64.StdEncoding.DecodeString(string(data)); err == nil {
+			data = decoded
+		}
+	}
+	return ParseFineTuneResults(bytes.NewReader(data))
+}
+
+// ResultMetrics downloads the results file of the fine-tune id and parses it
+// into per-step loss and accuracy series.
+func (ft *FineTunesAPI) ResultMetrics(ctx context.Context, id string) (*FineTuneMetrics, error) {
+	info, _, err := ft.RetrieveFineTune(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(info.ResultFiles) == 0 {
+		return nil, ErrNoResultFiles
+	}
+	return ft.openAIClient.resultMetrics(ctx, info.ResultFiles[0].ID)
+}
+
+// ResultMetrics downloads the results file of the fine-tuning job id and
+// parses it into per-step loss and accuracy series.
+func (fj *FineTuningJobsAPI) ResultMetrics(ctx context.Context, id string) (*FineTuneMetrics, error) {
+	job, _, err := fj.RetrieveFineTuningJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(job.ResultFiles) == 0 {
+		return nil, ErrNoResultFiles
+	}
+	return fj.openAIClient.resultMetrics(ctx, job.ResultFiles[0])
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FineTuneError is returned by WaitForFineTune when a job fails or is cancelled.
+type FineTuneError struct {
+	ID     string
+	Status string
+	// Reason is the message of the last error event, or of the last event
+	// when there is no error event.
+	Reason string
+}
+
+func (e *FineTuneError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("openai: fine-tune %s %s", e.ID, e.Status)
+	}
+	return fmt.Sprintf("openai: fine-tune %s %s: %s", e.ID, e.Status, e.Reason)
+}
+
+// FineTuneWaitOptions configures WaitForFineTune. The zero value is usable.
+type FineTuneWaitOptions struct {
+	// PollInterval is the delay between polls while new events keep arriving.
+	// Defaults to 5 seconds.
+	PollInterval time.Duration
+	// MaxPollInterval caps the delay, which doubles after every poll without
+	// new events. Defaults to one minute.
+	MaxPollInterval time.Duration
+	// Stream follows the job's events over a streaming connection and only
+	// polls once the stream closes, for the final status and any missed events.
+	// A stream that drops or fails temporarily falls back to polling; one the
+	// API rejects, such as for an unknown job, returns its error.
+	Stream bool
+	// OnEvent, when set, is called once for every event of the job, in order.
+	// Events are told apart by their ID, or by their time, level and message
+	// when the API does not report one.
+	OnEvent func(FineTuneEvent)
+}
+
+// isTerminalFineTuneStatus reports whether a job in status will not change anymore.
+func isTerminalFineTuneStatus(status string) bool {
+	switch status {
+	case FineTuneStatusSucceeded, FineTuneStatusFailed, FineTuneStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// WaitForFineTune blocks until the fine-tune job id succeeds, fails or is
+// cancelled, and returns its final state. A failed or cancelled job returns
+// the state together with a *FineTuneError.
+func (ft *FineTunesAPI) WaitForFineTune(ctx context.Context, id string, opts *FineTuneWaitOptions) (*FineTune, error) {
+	if opts == nil {
+		opts = &FineTuneWaitOptions{}
+	}
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	maxInterval := opts.MaxPollInterval
+	if maxInterval < interval {
+		maxInterval = time.Minute
+		if maxInterval < interval {
+			maxInterval = interval
+		}
+	}
+
+	seen := make(map[string]bool)
+	emit := func(ev FineTuneEvent) bool {
+		key := "id\x00" + ev.ID
+		if ev.ID == "" {
+			key = fmt.Sprintf("%d\x00%s\x00%s", ev.CreatedAt, ev.Level, ev.Message)
+		}
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+		if opts.OnEvent != nil {
+			opts.OnEvent(ev)
+		}
+		return true
+	}
+
+	if opts.Stream {
+		err := ft.StreamFineTuneEvents(ctx, id, func(ev FineTuneEvent) error {
+			emit(ev)
+			return nil
+		})
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// A dropped stream is not fatal: polling below picks up any missed events.
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && !apiErr.Temporary() {
+			return nil, err
+		}
+	}
+
+	delay := interval
+	for {
+		info, _, err := ft.RetrieveFineTune(ctx, id)
+		if err != nil && !isRetryable(err) {
+			return nil, err
+		}
+
+		if err == nil {
+			fresh := false
+			for _, ev := range info.Events {
+				if emit(ev) {
+					fresh = true
+				}
+			}
+
+			if isTerminalFineTuneStatus(info.Status) {
+				if info.Status != FineTuneStatusSucceeded {
+					return info, &FineTuneError{ID: id, Status: info.Status, Reason: failureReason(info.Events)}
+				}
+				return info, nil
+			}
+
+			if fresh {
+				delay = interval
+			} else if delay *= 2; delay > maxInterval {
+				delay = maxInterval
+			}
+		}
+
+		if err := sleepCtx(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// failureReason picks the message explaining why a job ended.
+func failureReason(events []FineTuneEvent) string {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Level == "error" {
+			return events[i].Message
+		}
+	}
+	if len(events) > 0 {
+		return events[len(events)-1].Message
+	}
+	return ""
+}
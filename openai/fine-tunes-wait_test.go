@@ -0,0 +1,76 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWaitForFineTuneDedupesByEventID(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/fine-tunes/ft-1/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"id\":\"ev-1\",\"created_at\":1,\"level\":\"info\",\"message\":\"Completed epoch\"}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+	mux.HandleFunc("GET /v1/fine-tunes/ft-1", func(w http.ResponseWriter, r *http.Request) {
+		// ev-2 repeats the time, level and message of ev-1 but is a new event.
+		fmt.Fprint(w, `{"id":"ft-1","status":"succeeded","events":[
+			{"id":"ev-1","created_at":1,"level":"info","message":"Completed epoch"},
+			{"id":"ev-2","created_at":1,"level":"info","message":"Completed epoch"}]}`)
+	})
+	c := newTestClient(t, mux)
+
+	var got []string
+	_, err := c.FineTunes.WaitForFineTune(context.Background(), "ft-1", &FineTuneWaitOptions{
+		PollInterval: time.Millisecond,
+		Stream:       true,
+		OnEvent:      func(ev FineTuneEvent) { got = append(got, ev.ID) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "ev-1" || got[1] != "ev-2" {
+		t.Errorf("events = %v, want [ev-1 ev-2]", got)
+	}
+}
+
+func TestWaitForFineTuneStreamErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"rejected", http.StatusNotFound, true},
+		{"temporary", http.StatusServiceUnavailable, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /v1/fine-tunes/ft-1/events", func(w http.ResponseWriter, r *http.Request) {
+				writeAPIError(w, tt.status, "stream failed")
+			})
+			mux.HandleFunc("GET /v1/fine-tunes/ft-1", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"id":"ft-1","status":"succeeded"}`)
+			})
+			c := newTestClient(t, mux)
+
+			_, err := c.FineTunes.WaitForFineTune(context.Background(), "ft-1", &FineTuneWaitOptions{
+				PollInterval: time.Millisecond,
+				Stream:       true,
+			})
+			var apiErr *APIError
+			if tt.wantErr && !(errors.As(err, &apiErr) && apiErr.StatusCode() == tt.status) {
+				t.Errorf("err = %v, want the stream's %d", err, tt.status)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("err = %v, want a fallback to polling", err)
+			}
+		})
+	}
+}
@@ -4,15 +4,29 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"iter"
 	"net/http"
+	"regexp"
 )
 
 type FineTunesAPI Api
 
+// Statuses of a fine-tune job.
+const (
+	FineTuneStatusPending   = "pending"
+	FineTuneStatusRunning   = "running"
+	FineTuneStatusSucceeded = "succeeded"
+	FineTuneStatusFailed    = "failed"
+	FineTuneStatusCancelled = "cancelled"
+)
+
 type FineTuneRequest struct {
 	TrainingFile                 string    `json:"training_file" validate:"required"`
-	ValidationFile               string    `json:"validation_file"`
+	ValidationFile               string    `json:"validation_file,omitempty"`
 	Model                        string    `json:"model,omitempty"`
 	NumEpochs                    int       `json:"n_epochs,omitempty"`
 	BatchSize                    *int      `json:"batch_size,omitempty"`
@@ -31,17 +45,24 @@ type FineTune struct {
 	Model           string          `json:"model"`
 	CreatedAt       int64           `json:"created_at"`
 	Events          []FineTuneEvent `json:"events"`
-	FineTunedModel  interface{}     `json:"fine_tuned_model"`
+	FineTunedModel  string          `json:"fine_tuned_model"`
 	Hyperparams     Hyperparams     `json:"hyperparams"`
 	OrganizationID  string          `json:"organization_id"`
-	ResultFiles     []interface{}   `json:"result_files"`
+	ResultFiles     []File          `json:"result_files"`
 	Status          string          `json:"status"`
-	ValidationFiles []interface{}   `json:"validation_files"`
-	TrainingFiles   []TrainingFile  `json:"training_files"`
+	ValidationFiles []File          `json:"validation_files"`
+	TrainingFiles   []File          `json:"training_files"`
 	UpdatedAt       int64           `json:"updated_at"`
 }
 
+// FineTuneInfo is the former name of FineTune.
+//
+// Deprecated: Use FineTune.
+type FineTuneInfo = FineTune
+
 type FineTuneEvent struct {
+	// ID is only reported by newer versions of the API.
+	ID        string `json:"id"`
 	Object    string `json:"object"`
 	CreatedAt int64  `json:"created_at"`
 	Level     string `json:"level"`
@@ -55,42 +76,21 @@ type Hyperparams struct {
 	PromptLossWeight       float64 `json:"prompt_loss_weight"`
 }
 
-type TrainingFile struct {
-	ID        string `json:"id"`
-	Object    string `json:"object"`
-	Bytes     int    `json:"bytes"`
-	CreatedAt int64  `json:"created_at"`
-	Filename  string `json:"filename"`
-	Purpose   string `json:"purpose"`
-}
+// TrainingFile is the former type of FineTune.TrainingFiles.
+//
+// Deprecated: Use File.
+type TrainingFile = File
 
 type FineTuneList struct {
-	Object string     `json:"object"`
-	Data   []FineTune `json:"data"`
+	Object  string     `json:"object"`
+	Data    []FineTune `json:"data"`
+	HasMore bool       `json:"has_more"`
 }
 
-type HyperParams struct {
-	BatchSize              int     `json:"batch_size"`
-	LearningRateMultiplier float64 `json:"learning_rate_multiplier"`
-	NEpochs                int     `json:"n_epochs"`
-	PromptLossWeight       float64 `json:"prompt_loss_weight"`
-}
-
-type FineTuneInfo struct {
-	ID              string          `json:"id"`
-	Object          string          `json:"object"`
-	Model           string          `json:"model"`
-	CreatedAt       int64           `json:"created_at"`
-	Events          []FineTuneEvent `json:"events"`
-	FineTunedModel  string          `json:"fine_tuned_model"`
-	Hyperparams     HyperParams     `json:"hyperparams"`
-	OrganizationID  string          `json:"organization_id"`
-	ResultFiles     []File          `json:"result_files"`
-	Status          string          `json:"status"`
-	ValidationFiles []File          `json:"validation_files"`
-	TrainingFiles   []File          `json:"training_files"`
-	UpdatedAt       int64           `json:"updated_at"`
-}
+// HyperParams is the former name of Hyperparams.
+//
+// Deprecated: Use Hyperparams.
+type HyperParams = Hyperparams
 
 type FineTuneEventList struct {
 	Object string          `json:"object"`
@@ -105,9 +105,14 @@ type DeleteModelResponse struct {
 
 // CreateFineTune creates a job that fine-tunes a specified model from a given dataset.
 // Response includes details of the enqueued job including job status and the name of the fine-tuned models once complete.
+// The request is checked with Preflight before it is sent.
 func (ft *FineTunesAPI) CreateFineTune(ctx context.Context, ftReq *FineTuneRequest) (*FineTune, *Response, error) {
+	if err := ft.Preflight(ctx, ftReq); err != nil {
+		return nil, nil, err
+	}
+
 	u := "v1/fine-tunes"
-	req, err := ft.openAIClient.NewRequest(http.MethodPost, u, nil)
+	req, err := ft.openAIClient.NewRequest(http.MethodPost, u, ftReq)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -122,9 +127,10 @@ func (ft *FineTunesAPI) CreateFineTune(ctx context.Context, ftReq *FineTuneReque
 	return fTResp, resp, nil
 }
 
-// List your organization's fine-tuning jobs
-func (ft *FineTunesAPI) List(ctx context.Context) (*FineTuneList, *Response, error) {
-	u := "v1/fine-tunes"
+// List your organization's fine-tuning jobs.
+// Passing opts returns one page of them.
+func (ft *FineTunesAPI) List(ctx context.Context, opts ...*ListOptions) (*FineTuneList, *Response, error) {
+	u := firstListOptions(opts).encode("v1/fine-tunes")
 	req, err := ft.openAIClient.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, nil, err
@@ -140,15 +146,27 @@ func (ft *FineTunesAPI) List(ctx context.Context) (*FineTuneList, *Response, err
 	return fTLResp, resp, nil
 }
 
+// ListAll iterates over all of your organization's fine-tuning jobs, fetching
+// pages as needed.
+func (ft *FineTunesAPI) ListAll(ctx context.Context, opts *ListOptions) iter.Seq2[FineTune, error] {
+	return paginate(ctx, opts, func(ctx context.Context, o *ListOptions) (page[FineTune], error) {
+		l, _, err := ft.List(ctx, o)
+		if err != nil {
+			return page[FineTune]{}, err
+		}
+		return page[FineTune]{items: l.Data, hasMore: l.HasMore}, nil
+	}, func(f FineTune) string { return f.ID })
+}
+
 // RetrieveFineTune gets info about the fine-tune job.
-func (ft *FineTunesAPI) RetrieveFineTune(ctx context.Context, id string) (*FineTuneInfo, *Response, error) {
+func (ft *FineTunesAPI) RetrieveFineTune(ctx context.Context, id string) (*FineTune, *Response, error) {
 	u := fmt.Sprintf("v1/fine-tunes/%s", id)
 	req, err := ft.openAIClient.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	fTIResp := new(FineTuneInfo)
+	fTIResp := new(FineTune)
 
 	resp, err := ft.openAIClient.Do(ctx, req, fTIResp)
 	if err != nil {
@@ -159,14 +177,14 @@ func (ft *FineTunesAPI) RetrieveFineTune(ctx context.Context, id string) (*FineT
 }
 
 // CancelFineTune immediately cancel a fine-tune job.
-func (ft *FineTunesAPI) CancelFineTune(ctx context.Context, id string) (*FineTuneInfo, *Response, error) {
+func (ft *FineTunesAPI) CancelFineTune(ctx context.Context, id string) (*FineTune, *Response, error) {
 	u := fmt.Sprintf("v1/fine-tunes/%s/cancel", id)
 	req, err := ft.openAIClient.NewRequest(http.MethodPost, u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	fTIResp := new(FineTuneInfo)
+	fTIResp := new(FineTune)
 
 	resp, err := ft.openAIClient.Do(ctx, req, fTIResp)
 	if err != nil {
@@ -179,9 +197,7 @@ func (ft *FineTunesAPI) CancelFineTune(ctx context.Context, id string) (*FineTun
 // ListFineTuneEvents gets fine-grained status updates for a fine-tune job.
 func (ft *FineTunesAPI) ListFineTuneEvents(ctx context.Context, id string) (*FineTuneEventList, *Response, error) {
 	u := fmt.Sprintf("v1/fine-tunes/%s/events", id)
-
-	// TODO: Investigate stream query parameter
-	req, err := ft.openAIClient.NewRequest(http.MethodPost, u, nil)
+	req, err := ft.openAIClient.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -196,6 +212,46 @@ func (ft *FineTunesAPI) ListFineTuneEvents(ctx context.Context, id string) (*Fin
 	return fTELResp, resp, nil
 }
 
+// StreamFineTuneEvents streams the events of a fine-tune job as they happen,
+// calling fn for each one, starting with the events emitted so far.
+// The stream ends when the job finishes or fn returns an error, which is
+// then returned.
+func (ft *FineTunesAPI) StreamFineTuneEvents(ctx context.Context, id string, fn func(FineTuneEvent) error) error {
+	u := fmt.Sprintf("v1/fine-tunes/%s/events?stream=true", id)
+	req, err := ft.openAIClient.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := ft.openAIClient.doStream(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := newSSEDecoder(resp.Body)
+	for {
+		ev, err := dec.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if string(ev.Data) == sseDone {
+			return nil
+		}
+
+		var event FineTuneEvent
+		if err := json.Unmarshal(ev.Data, &event); err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+}
+
 // Delete a fine-tuned model.
 // You must have the Owner role in your organization.
 func (ft *FineTunesAPI) Delete(ctx context.Context, model string) (*DeleteModelResponse, *Response, error) {
@@ -214,3 +270,91 @@ func (ft *FineTunesAPI) Delete(ctx context.Context, model string) (*DeleteModelR
 
 	return delResp, resp, nil
 }
+
+// Limits checked by FineTuneRequest.Validate.
+const (
+	MaxFineTuneSuffixLength = 40
+	MaxFineTuneEpochs       = 50
+	MaxFineTuneBatchSize    = 256
+)
+
+var fineTuneSuffixChars = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
+// Validate checks the fields of the request that can be checked without
+// calling the API. It returns ValidationErrors listing every problem.
+func (r *FineTuneRequest) Validate() error {
+	var errs ValidationErrors
+
+	if r.TrainingFile == "" {
+		errs.add("training_file", "is required")
+	}
+	if r.NumEpochs < 0 || r.NumEpochs > MaxFineTuneEpochs {
+		errs.add("n_epochs", fmt.Sprintf("must be between 1 and %d", MaxFineTuneEpochs))
+	}
+	if r.BatchSize != nil && (*r.BatchSize < 1 || *r.BatchSize > MaxFineTuneBatchSize) {
+		errs.add("batch_size", fmt.Sprintf("must be between 1 and %d", MaxFineTuneBatchSize))
+	}
+	if r.LearningRateMultiplier != nil && *r.LearningRateMultiplier <= 0 {
+		errs.add("learning_rate_multiplier", "must be positive")
+	}
+	if r.PromptLossWeight != nil && (*r.PromptLossWeight < 0 || *r.PromptLossWeight > 1) {
+		errs.add("prompt_loss_weight", "must be between 0 and 1")
+	}
+	if r.ClassificationNumClasses != nil && *r.ClassificationNumClasses < 2 {
+		errs.add("classification_n_classes", "must be at least 2")
+	}
+	if r.ClassificationPositiveClass != "" && !r.ComputeClassificationMetrics {
+		errs.add("classification_positive_class", "requires compute_classification_metrics")
+	}
+	for _, b := range r.ClassificationBetas {
+		if b <= 0 {
+			errs.add("classification_betas", "must all be positive")
+			break
+		}
+	}
+	if len(r.Suffix) > MaxFineTuneSuffixLength {
+		errs.add("suffix", fmt.Sprintf("must be at most %d characters", MaxFineTuneSuffixLength))
+	}
+	if !fineTuneSuffixChars.MatchString(r.Suffix) {
+		errs.add("suffix", "may only contain letters, digits, '-' and '_'")
+	}
+
+	return errs.err()
+}
+
+// Preflight validates ftReq and checks that the training and validation files
+// it references exist and were uploaded for fine-tuning. Files still being
+// processed are waited for.
+func (ft *FineTunesAPI) Preflight(ctx context.Context, ftReq *FineTuneRequest) error {
+	var errs ValidationErrors
+	if err := ftReq.Validate(); err != nil {
+		errs = append(errs, err.(ValidationErrors)...)
+	}
+
+	files := []struct{ field, id string }{
+		{"training_file", ftReq.TrainingFile},
+		{"validation_file", ftReq.ValidationFile},
+	}
+	for _, f := range files {
+		if f.id == "" {
+			continue
+		}
+		file, err := ft.openAIClient.File.WaitForFileProcessed(ctx, f.id)
+		var (
+			apiErr  *APIError
+			procErr *FileProcessingError
+		)
+		switch {
+		case errors.As(err, &apiErr) && apiErr.StatusCode() == http.StatusNotFound:
+			errs.add(f.field, fmt.Sprintf("file %q does not exist", f.id))
+		case errors.As(err, &procErr):
+			errs.add(f.field, procErr.Error())
+		case err != nil:
+			return err
+		case file.Purpose != FilePurposeFineTune:
+			errs.add(f.field, fmt.Sprintf("file %q has purpose %q, want %q", f.id, file.Purpose, FilePurposeFineTune))
+		}
+	}
+
+	return errs.err()
+}
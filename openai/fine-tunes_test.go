@@ -0,0 +1,178 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// fineTuneServer fakes the files and fine-tunes endpoints. Files are looked up
+// in files by ID; unknown IDs are answered with a 404. Every fine-tune
+// creation request is passed to created.
+func fineTuneServer(t *testing.T, files map[string]string, created func(FineTuneRequest)) *OpenAIClient {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		file, ok := files[r.PathValue("id")]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "No such File object: "+r.PathValue("id"))
+			return
+		}
+		fmt.Fprint(w, file)
+	})
+	mux.HandleFunc("POST /v1/fine-tunes", func(w http.ResponseWriter, r *http.Request) {
+		var ftReq FineTuneRequest
+		if err := json.NewDecoder(r.Body).Decode(&ftReq); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		created(ftReq)
+		fmt.Fprintf(w, `{"id":"ft-1","object":"fine-tune","model":%q,"status":"pending"}`, ftReq.Model)
+	})
+	mux.HandleFunc("GET /v1/fine-tunes/ft-1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"ft-1","object":"fine-tune","model":"curie","status":"running"}`)
+	})
+	mux.HandleFunc("GET /v1/fine-tunes/ft-1/events", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"object":"list","data":[
+			{"object":"fine-tune-event","created_at":1,"level":"info","message":"Created fine-tune: ft-1"},
+			{"object":"fine-tune-event","created_at":2,"level":"info","message":"Fine-tune started"}]}`)
+	})
+	mux.HandleFunc("POST /v1/fine-tunes/ft-1/cancel", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"ft-1","object":"fine-tune","model":"curie","status":"cancelled"}`)
+	})
+	return newTestClient(t, mux)
+}
+
+var fineTuneFiles = map[string]string{
+	"file-train":  `{"id":"file-train","object":"file","purpose":"fine-tune","status":"processed"}`,
+	"file-valid":  `{"id":"file-valid","object":"file","purpose":"fine-tune","status":"processed"}`,
+	"file-search": `{"id":"file-search","object":"file","purpose":"assistants","status":"processed"}`,
+	"file-broken": `{"id":"file-broken","object":"file","purpose":"fine-tune","status":"error","status_details":"line 3 is not valid JSON"}`,
+}
+
+func TestFineTuneLifecycle(t *testing.T) {
+	var sent []FineTuneRequest
+	c := fineTuneServer(t, fineTuneFiles, func(r FineTuneRequest) { sent = append(sent, r) })
+	ctx := context.Background()
+
+	ft, _, err := c.FineTunes.CreateFineTune(ctx, &FineTuneRequest{
+		TrainingFile:   "file-train",
+		ValidationFile: "file-valid",
+		Model:          "curie",
+		NumEpochs:      4,
+		Suffix:         "my-model_1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ft.ID != "ft-1" || ft.Status != FineTuneStatusPending {
+		t.Errorf("created %+v, want ft-1 pending", ft)
+	}
+	if len(sent) != 1 || sent[0].TrainingFile != "file-train" || sent[0].NumEpochs != 4 || sent[0].Suffix != "my-model_1" {
+		t.Fatalf("server received %+v", sent)
+	}
+
+	ft, _, err = c.FineTunes.RetrieveFineTune(ctx, "ft-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ft.Status != FineTuneStatusRunning {
+		t.Errorf("retrieved status %q, want %q", ft.Status, FineTuneStatusRunning)
+	}
+
+	events, _, err := c.FineTunes.ListFineTuneEvents(ctx, "ft-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events.Data) != 2 || events.Data[1].Message != "Fine-tune started" {
+		t.Errorf("events = %+v", events.Data)
+	}
+
+	ft, _, err = c.FineTunes.CancelFineTune(ctx, "ft-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ft.Status != FineTuneStatusCancelled {
+		t.Errorf("cancelled status %q, want %q", ft.Status, FineTuneStatusCancelled)
+	}
+}
+
+func TestPreflightErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   FineTuneRequest
+		field string
+		want  string
+	}{
+		{"missing training file", FineTuneRequest{}, "training_file", "is required"},
+		{"unknown training file", FineTuneRequest{TrainingFile: "file-gone"}, "training_file", `"file-gone" does not exist`},
+		{"unknown validation file", FineTuneRequest{TrainingFile: "file-train", ValidationFile: "file-gone"}, "validation_file", `"file-gone" does not exist`},
+		{"wrong purpose", FineTuneRequest{TrainingFile: "file-search"}, "training_file", `purpose "assistants"`},
+		{"failed processing", FineTuneRequest{TrainingFile: "file-broken"}, "training_file", "line 3 is not valid JSON"},
+		{"too many epochs", FineTuneRequest{TrainingFile: "file-train", NumEpochs: MaxFineTuneEpochs + 1}, "n_epochs", "must be between"},
+		{"negative epochs", FineTuneRequest{TrainingFile: "file-train", NumEpochs: -1}, "n_epochs", "must be between"},
+		{"zero batch size", FineTuneRequest{TrainingFile: "file-train", BatchSize: intp(0)}, "batch_size", "must be between"},
+		{"huge batch size", FineTuneRequest{TrainingFile: "file-train", BatchSize: intp(MaxFineTuneBatchSize + 1)}, "batch_size", "must be between"},
+		{"zero learning rate", FineTuneRequest{TrainingFile: "file-train", LearningRateMultiplier: Float64(0)}, "learning_rate_multiplier", "must be positive"},
+		{"prompt loss weight", FineTuneRequest{TrainingFile: "file-train", PromptLossWeight: Float64(1.5)}, "prompt_loss_weight", "between 0 and 1"},
+		{"long suffix", FineTuneRequest{TrainingFile: "file-train", Suffix: strings.Repeat("a", MaxFineTuneSuffixLength+1)}, "suffix", "at most"},
+		{"suffix charset", FineTuneRequest{TrainingFile: "file-train", Suffix: "my model!"}, "suffix", "may only contain"},
+	}
+	c := fineTuneServer(t, fineTuneFiles, func(r FineTuneRequest) {
+		t.Errorf("invalid request %+v reached the API", r)
+	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := c.FineTunes.CreateFineTune(context.Background(), &tt.req)
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("err = %v, want ValidationErrors", err)
+			}
+			if len(errs) != 1 || errs[0].Field != tt.field || !strings.Contains(errs[0].Reason, tt.want) {
+				t.Errorf("errs = %v, want one %s error containing %q", errs, tt.field, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreflightCollectsEveryError(t *testing.T) {
+	c := fineTuneServer(t, fineTuneFiles, nil)
+	err := c.FineTunes.Preflight(context.Background(), &FineTuneRequest{
+		TrainingFile:   "file-search",
+		ValidationFile: "file-gone",
+		NumEpochs:      MaxFineTuneEpochs + 1,
+		Suffix:         "bad suffix",
+	})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 4 {
+		t.Errorf("err = %v, want four validation errors", err)
+	}
+}
+
+func intp(n int) *int { return &n }
+
+func TestFineTuneRequestOmitsEmptyValidationFile(t *testing.T) {
+	c := NewClient(nil)
+	for ftReq, want := range map[*FineTuneRequest]bool{
+		{TrainingFile: "file-train"}:                               false,
+		{TrainingFile: "file-train", ValidationFile: "file-valid"}: true,
+	} {
+		req, err := c.NewRequest(http.MethodPost, "v1/fine-tunes", ftReq)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(string(body), "validation_file"); got != want {
+			t.Errorf("body %s: holds validation_file = %v, want %v", body, got, want)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+// FineTuningJobsAPI manages fine-tuning jobs through the /v1/fine_tuning/jobs
+// endpoints, which replace the legacy /v1/fine-tunes endpoints of FineTunesAPI.
+type FineTuningJobsAPI Api
+
+// Statuses of a fine-tuning job.
+const (
+	FineTuningStatusValidatingFiles = "validating_files"
+	FineTuningStatusQueued          = "queued"
+	FineTuningStatusRunning         = "running"
+	FineTuningStatusSucceeded       = "succeeded"
+	FineTuningStatusFailed          = "failed"
+	FineTuningStatusCancelled       = "cancelled"
+)
+
+// Fine-tuning methods.
+const (
+	FineTuningMethodSupervised    = "supervised"
+	FineTuningMethodDPO           = "dpo"
+	FineTuningMethodReinforcement = "reinforcement"
+)
+
+// AutoInt is an integer hyperparameter that can be set to "auto" to let the
+// API choose the value.
+type AutoInt struct {
+	Auto  bool
+	Value int
+}
+
+func (a AutoInt) MarshalJSON() ([]byte, error) {
+	if a.Auto {
+		return []byte(`"auto"`), nil
+	}
+	return json.Marshal(a.Value)
+}
+
+func (a *AutoInt) UnmarshalJSON(data []byte) error {
+	if string(data) == `"auto"` {
+		*a = AutoInt{Auto: true}
+		return nil
+	}
+	*a = AutoInt{}
+	return json.Unmarshal(data, &a.Value)
+}
+
+// AutoFloat is a floating point hyperparameter that can be set to "auto" to
+// let the API choose the value.
+type AutoFloat struct {
+	Auto  bool
+	Value float64
+}
+
+func (a AutoFloat) MarshalJSON() ([]byte, error) {
+	if a.Auto {
+		return []byte(`"auto"`), nil
+	}
+	return json.Marshal(a.Value)
+}
+
+func (a *AutoFloat) UnmarshalJSON(data []byte) error {
+	if string(data) == `"auto"` {
+		*a = AutoFloat{Auto: true}
+		return nil
+	}
+	*a = AutoFloat{}
+	return json.Unmarshal(data, &a.Value)
+}
+
+// FineTuningHyperparameters configure a fine-tuning job. Unset fields are
+// chosen by the API. Beta only applies to DPO, and ComputeMultiplier,
+// EvalInterval, EvalSamples and ReasoningEffort only to reinforcement tuning.
+type FineTuningHyperparameters struct {
+	BatchSize              *AutoInt   `json:"batch_size,omitempty"`
+	LearningRateMultiplier *AutoFloat `json:"learning_rate_multiplier,omitempty"`
+	NEpochs                *AutoInt   `json:"n_epochs,omitempty"`
+	Beta                   *AutoFloat `json:"beta,omitempty"`
+	ComputeMultiplier      *AutoFloat `json:"compute_multiplier,omitempty"`
+	EvalInterval           *AutoInt   `json:"eval_interval,omitempty"`
+	EvalSamples            *AutoInt   `json:"eval_samples,omitempty"`
+	ReasoningEffort        string     `json:"reasoning_effort,omitempty"`
+}
+
+// FineTuningMethod selects how the model is tuned. Type is one of the
+// FineTuningMethod constants and the matching field holds its settings.
+type FineTuningMethod struct {
+	Type          string               `json:"type"`
+	Supervised    *SupervisedMethod    `json:"supervised,omitempty"`
+	DPO           *DPOMethod           `json:"dpo,omitempty"`
+	Reinforcement *ReinforcementMethod `json:"reinforcement,omitempty"`
+}
+
+type SupervisedMethod struct {
+	Hyperparameters *FineTuningHyperparameters `json:"hyperparameters,omitempty"`
+}
+
+type DPOMethod struct {
+	Hyperparameters *FineTuningHyperparameters `json:"hyperparameters,omitempty"`
+}
+
+type ReinforcementMethod struct {
+	// Grader scores the model's outputs, for example
+	// {"type": "string_check", "name": "...", "input": "...", "reference": "...", "operation": "eq"}.
+	Grader          interface{}                `json:"grader" binding:"required"`
+	Hyperparameters *FineTuningHyperparameters `json:"hyperparameters,omitempty"`
+}
+
+// FineTuningIntegration reports job progress to a third party. Only "wandb" is supported.
+type FineTuningIntegration struct {
+	Type  string            `json:"type"`
+	Wandb *WandbIntegration `json:"wandb,omitempty"`
+}
+
+// WandbIntegration logs metrics of the job to a Weights and Biases project.
+type WandbIntegration struct {
+	Project string   `json:"project" binding:"required"`
+	Name    string   `json:"name,omitempty"`
+	Entity  string   `json:"entity,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+type FineTuningJobRequest struct {
+	Model          string `json:"model" binding:"required"`
+	TrainingFile   string `json:"training_file" binding:"required"`
+	ValidationFile string `json:"validation_file,omitempty"`
+	// Hyperparameters is deprecated by the API in favour of the ones in Method.
+	Hyperparameters *FineTuningHyperparameters `json:"hyperparameters,omitempty"`
+	Suffix          string                     `json:"suffix,omitempty"`
+	Seed            *int                       `json:"seed,omitempty"`
+	Integrations    []FineTuningIntegration    `json:"integrations,omitempty"`
+	Method          *FineTuningMethod          `json:"method,omitempty"`
+	Metadata        map[string]string          `json:"metadata,omitempty"`
+}
+
+type FineTuningJob struct {
+	ID                 string                    `json:"id"`
+	Object             string                    `json:"object"`
+	CreatedAt          int64                     `json:"created_at"`
+	FinishedAt         int64                     `json:"finished_at"`
+	EstimatedFinish    int64                     `json:"estimated_finish"`
+	Model              string                    `json:"model"`
+	FineTunedModel     string                    `json:"fine_tuned_model"`
+	OrganizationID     string                    `json:"organization_id"`
+	Status             string                    `json:"status"`
+	Hyperparameters    FineTuningHyperparameters `json:"hyperparameters"`
+	TrainingFile       string                    `json:"training_file"`
+	ValidationFile     string                    `json:"validation_file"`
+	ResultFiles        []string                  `json:"result_files"`
+	TrainedTokens      int                       `json:"trained_tokens"`
+	Error              *FineTuningJobError       `json:"error"`
+	Seed               int                       `json:"seed"`
+	Integrations       []FineTuningIntegration   `json:"integrations"`
+	Method             *FineTuningMethod         `json:"method"`
+	Metadata           map[string]string         `json:"metadata"`
+	UserProvidedSuffix string                    `json:"user_provided_suffix"`
+}
+
+type FineTuningJobError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Param   string `json:"param"`
+}
+
+type FineTuningJobList struct {
+	Object  string          `json:"object"`
+	Data    []FineTuningJob `json:"data"`
+	HasMore bool            `json:"has_more"`
+}
+
+type FineTuningJobEvent struct {
+	ID        string      `json:"id"`
+	Object    string      `json:"object"`
+	CreatedAt int64       `json:"created_at"`
+	Level     string      `json:"level"`
+	Message   string      `json:"message"`
+	Type      string      `json:"type"`
+	Data      interface{} `json:"data"`
+}
+
+type FineTuningJobEventList struct {
+	Object  string               `json:"object"`
+	Data    []FineTuningJobEvent `json:"data"`
+	HasMore bool                 `json:"has_more"`
+}
+
+type FineTuningCheckpoint struct {
+	ID                       string                      `json:"id"`
+	Object                   string                      `json:"object"`
+	CreatedAt                int64                       `json:"created_at"`
+	FineTunedModelCheckpoint string                      `json:"fine_tuned_model_checkpoint"`
+	StepNumber               int                         `json:"step_number"`
+	Metrics                  FineTuningCheckpointMetrics `json:"metrics"`
+	FineTuningJobID          string                      `json:"fine_tuning_job_id"`
+}
+
+type FineTuningCheckpointMetrics struct {
+	Step                       float64 `json:"step"`
+	TrainLoss                  float64 `json:"train_loss"`
+	TrainMeanTokenAccuracy     float64 `json:"train_mean_token_accuracy"`
+	ValidLoss                  float64 `json:"valid_loss"`
+	ValidMeanTokenAccuracy     float64 `json:"valid_mean_token_accuracy"`
+	FullValidLoss              float64 `json:"full_valid_loss"`
+	FullValidMeanTokenAccuracy float64 `json:"full_valid_mean_token_accuracy"`
+}
+
+type FineTuningCheckpointList struct {
+	Object  string                 `json:"object"`
+	Data    []FineTuningCheckpoint `json:"data"`
+	HasMore bool                   `json:"has_more"`
+	FirstID string                 `json:"first_id"`
+	LastID  string                 `json:"last_id"`
+}
+
+// CreateFineTuningJob creates a job that fine-tunes a specified model from a given dataset.
+// Response includes details of the enqueued job including job status and the name of the fine-tuned models once complete.
+// Training and validation files still being processed are waited for first.
+func (fj *FineTuningJobsAPI) CreateFineTuningJob(ctx context.Context, jobReq *FineTuningJobRequest) (*FineTuningJob, *Response, error) {
+	for _, id := range []string{jobReq.TrainingFile, jobReq.ValidationFile} {
+		if id == "" {
+			continue
+		}
+		if _, err := fj.openAIClient.File.WaitForFileProcessed(ctx, id); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	u := "v1/fine_tuning/jobs"
+	req, err := fj.openAIClient.NewRequest(http.MethodPost, u, jobReq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	job := new(FineTuningJob)
+
+	resp, err := fj.openAIClient.Do(ctx, req, job)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return job, resp, nil
+}
+
+// List lists your organization's fine-tuning jobs, newest first.
+func (fj *FineTuningJobsAPI) List(ctx context.Context, opts *ListOptions) (*FineTuningJobList, *Response, error) {
+	u := opts.encode("v1/fine_tuning/jobs")
+	req, err := fj.openAIClient.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list := new(FineTuningJobList)
+
+	resp, err := fj.openAIClient.Do(ctx, req, list)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return list, resp, nil
+}
+
+// RetrieveFineTuningJob gets info about a fine-tuning job.
+func (fj *FineTuningJobsAPI) RetrieveFineTuningJob(ctx context.Context, id string) (*FineTuningJob, *Response, error) {
+	u := fmt.Sprintf("v1/fine_tuning/jobs/%s", id)
+	req, err := fj.openAIClient.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	job := new(FineTuningJob)
+
+	resp, err := fj.openAIClient.Do(ctx, req, job)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return job, resp, nil
+}
+
+// CancelFineTuningJob immediately cancels a fine-tuning job.
+func (fj *FineTuningJobsAPI) CancelFineTuningJob(ctx context.Context, id string) (*FineTuningJob, *Response, error) {
+	u := fmt.Sprintf("v1/fine_tuning/jobs/%s/cancel", id)
+	req, err := fj.openAIClient.NewRequest(http.MethodPost, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	job := new(FineTuningJob)
+
+	resp, err := fj.openAIClient.Do(ctx, req, job)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return job, resp, nil
+}
+
+// ListFineTuningJobEvents gets status updates for a fine-tuning job, newest first.
+func (fj *FineTuningJobsAPI) ListFineTuningJobEvents(ctx context.Context, id string, opts *ListOptions) (*FineTuningJobEventList, *Response, error) {
+	u := opts.encode(fmt.Sprintf("v1/fine_tuning/jobs/%s/events", id))
+	req, err := fj.openAIClient.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events := new(FineTuningJobEventList)
+
+	resp, err := fj.openAIClient.Do(ctx, req, events)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return events, resp, nil
+}
+
+// ListFineTuningJobCheckpoints lists the checkpoints saved during a fine-tuning job, newest first.
+func (fj *FineTuningJobsAPI) ListFineTuningJobCheckpoints(ctx context.Context, id string, opts *ListOptions) (*FineTuningCheckpointList, *Response, error) {
+	u := opts.encode(fmt.Sprintf("v1/fine_tuning/jobs/%s/checkpoints", id))
+	req, err := fj.openAIClient.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checkpoints := new(FineTuningCheckpointList)
+
+	resp, err := fj.openAIClient.Do(ctx, req, checkpoints)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return checkpoints, resp, nil
+}
+
+// ListAll iterates over all of your organization's fine-tuning jobs, fetching
+// pages as needed.
+func (fj *FineTuningJobsAPI) ListAll(ctx context.Context, opts *ListOptions) iter.Seq2[FineTuningJob, error] {
+	return paginate(ctx, opts, func(ctx context.Context, o *ListOptions) (page[FineTuningJob], error) {
+		l, _, err := fj.List(ctx, o)
+		if err != nil {
+			return page[FineTuningJob]{}, err
+		}
+		return page[FineTuningJob]{items: l.Data, hasMore: l.HasMore}, nil
+	}, func(j FineTuningJob) string { return j.ID })
+}
+
+// ListAllFineTuningJobEvents iterates over all events of a fine-tuning job,
+// fetching pages as needed.
+func (fj *FineTuningJobsAPI) ListAllFineTuningJobEvents(ctx context.Context, id string, opts *ListOptions) iter.Seq2[FineTuningJobEvent, error] {
+	return paginate(ctx, opts, func(ctx context.Context, o *ListOptions) (page[FineTuningJobEvent], error) {
+		l, _, err := fj.ListFineTuningJobEvents(ctx, id, o)
+		if err != nil {
+			return page[FineTuningJobEvent]{}, err
+		}
+		return page[FineTuningJobEvent]{items: l.Data, hasMore: l.HasMore}, nil
+	}, func(e FineTuningJobEvent) string { return e.ID })
+}
+
+// ListAllFineTuningJobCheckpoints iterates over all checkpoints of a
+// fine-tuning job, fetching pages as needed.
+func (fj *FineTuningJobsAPI) ListAllFineTuningJobCheckpoints(ctx context.Context, id string, opts *ListOptions) iter.Seq2[FineTuningCheckpoint, error] {
+	return paginate(ctx, opts, func(ctx context.Context, o *ListOptions) (page[FineTuningCheckpoint], error) {
+		l, _, err := fj.ListFineTuningJobCheckpoints(ctx, id, o)
+		if err != nil {
+			return page[FineTuningCheckpoint]{}, err
+		}
+		return page[FineTuningCheckpoint]{items: l.Data, hasMore: l.HasMore, lastID: l.LastID}, nil
+	}, func(c FineTuningCheckpoint) string { return c.ID })
+}
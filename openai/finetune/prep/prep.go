@@ -0,0 +1,416 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+// Package prep validates JSONL fine-tuning datasets before they are uploaded,
+// in the spirit of the Python client's fine_tunes.prepare_data tool.
+package prep
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/AGMETEOR/openai-go/openai"
+	"github.com/AGMETEOR/openai-go/openai/tokenizer"
+)
+
+// Format is the shape of the examples in a dataset.
+type Format int
+
+const (
+	FormatUnknown Format = iota
+	// FormatPromptCompletion lines look like {"prompt": "...", "completion": "..."}.
+	FormatPromptCompletion
+	// FormatChat lines look like {"messages": [{"role": "...", "content": "..."}]}.
+	FormatChat
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatPromptCompletion:
+		return "prompt-completion"
+	case FormatChat:
+		return "chat"
+	}
+	return "unknown"
+}
+
+// Severity tells whether an issue makes an example unusable.
+type Severity int
+
+const (
+	// Warning issues are reported but the example is kept.
+	Warning Severity = iota
+	// Error issues drop the example from the cleaned output.
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Issue is a problem found on a line of the dataset.
+type Issue struct {
+	Line     int
+	Severity Severity
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("line %d: %s: %s", i.Line, i.Severity, i.Message)
+}
+
+// DefaultModel is the model token counts and prices are computed for when
+// Options.Model is empty.
+const DefaultModel = "gpt-4o-mini"
+
+// Options configures Analyze. The zero value is usable.
+type Options struct {
+	// Model is the model to be fine-tuned. Defaults to DefaultModel. Analyze
+	// fails if the tokenizer has no encoding for it.
+	Model string
+	// MaxTokensPerExample is the largest training example the model accepts.
+	// Defaults to the model's fine-tuning limit, which can be lower than its
+	// context window, or 4096 if it is unknown.
+	MaxTokensPerExample int
+	// Epochs is the number of epochs the cost estimate is made for. Defaults to 3.
+	Epochs int
+	// KeepDuplicates keeps repeated examples in the cleaned output.
+	KeepDuplicates bool
+}
+
+// exampleTokenLimits maps model name prefixes to the most tokens a training
+// example may have when fine-tuning the model. Longer prefixes are listed first.
+var exampleTokenLimits = []struct {
+	prefix string
+	tokens int
+}{
+	{"gpt-3.5-turbo-0613", 4096},
+	{"gpt-3.5-turbo", 16385},
+	{"gpt-4o", 65536},
+	{"gpt-4.1", 65536},
+	{"gpt-4", 8192},
+	{"davinci-002", 16384},
+	{"babbage-002", 16384},
+}
+
+// exampleTokenLimit returns the most tokens a training example for model may
+// have. Fine-tuned models, which can be trained further, share the limit of
+// their base model.
+func exampleTokenLimit(model string) int {
+	model = strings.TrimPrefix(model, "ft:")
+	for _, l := range exampleTokenLimits {
+		if strings.HasPrefix(model, l.prefix) {
+			return l.tokens
+		}
+	}
+	return 4096
+}
+
+// Report summarizes a dataset.
+type Report struct {
+	Model  string
+	Format Format
+
+	// Lines is the number of non-blank lines; Valid the number of examples
+	// without errors, of which Duplicates repeat an earlier example.
+	Lines      int
+	Valid      int
+	Duplicates int
+	TooLong    int
+
+	Issues []Issue
+
+	// Token counts cover the valid examples that fit in the model.
+	TotalTokens  int
+	MinTokens    int
+	MaxTokens    int
+	MedianTokens int
+
+	// TrainingTokensPerEpoch is what one epoch over the cleaned dataset is
+	// billed for, and CostPerEpoch its price in US dollars. Cost is zero when
+	// the model's training price is not known.
+	TrainingTokensPerEpoch int
+	Epochs                 int
+	CostPerEpoch           float64
+	Cost                   float64
+
+	clean [][]byte
+}
+
+// HasErrors reports whether any line has an error.
+func (r *Report) HasErrors() bool {
+	for _, i := range r.Issues {
+		if i.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// Kept returns the number of examples WriteCleaned writes.
+func (r *Report) Kept() int {
+	return len(r.clean)
+}
+
+// WriteCleaned writes the examples without errors, that fit in the model and,
+// unless Options.KeepDuplicates was set, are not duplicates, one per line.
+func (r *Report) WriteCleaned(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, line := range r.clean {
+		if _, err := bw.Write(line); err != nil {
+			return err
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Analyze reads a JSONL dataset from rd and reports on it.
+func Analyze(rd io.Reader, opts Options) (*Report, error) {
+	if opts.Model == "" {
+		opts.Model = DefaultModel
+	}
+	if opts.Epochs <= 0 {
+		opts.Epochs = 3
+	}
+	info, _ := openai.DefaultModelRegistry.Lookup(opts.Model)
+	if opts.MaxTokensPerExample <= 0 {
+		opts.MaxTokensPerExample = exampleTokenLimit(opts.Model)
+	}
+
+	count, err := tokenizer.CounterForModel(opts.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := &Report{Model: opts.Model, Epochs: opts.Epochs}
+	a := &analyzer{opts: opts, rep: rep, count: count, seen: make(map[[32]byte]int)}
+
+	sc := bufio.NewScanner(rd)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		rep.Lines++
+		a.line(n, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	a.finish(info)
+	return rep, nil
+}
+
+type analyzer struct {
+	opts   Options
+	rep    *Report
+	count  func(string) int
+	seen   map[[32]byte]int
+	tokens []int
+}
+
+func (a *analyzer) issue(line int, sev Severity, format string, args ...interface{}) {
+	a.rep.Issues = append(a.rep.Issues, Issue{Line: line, Severity: sev, Message: fmt.Sprintf(format, args...)})
+}
+
+func (a *analyzer) line(n int, line []byte) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(line, &obj); err != nil {
+		a.issue(n, Error, "malformed JSON: %v", err)
+		return
+	}
+
+	format := FormatUnknown
+	switch {
+	case obj["messages"] != nil:
+		format = FormatChat
+	case obj["prompt"] != nil || obj["completion"] != nil:
+		format = FormatPromptCompletion
+	default:
+		a.issue(n, Error, `example has neither "messages" nor "prompt"/"completion"`)
+		return
+	}
+	if a.rep.Format == FormatUnknown {
+		a.rep.Format = format
+	} else if format != a.rep.Format {
+		a.issue(n, Error, "%s example in a %s dataset", format, a.rep.Format)
+		return
+	}
+
+	var (
+		tokens int
+		ok     bool
+	)
+	if format == FormatChat {
+		tokens, ok = a.chat(n, obj)
+	} else {
+		tokens, ok = a.promptCompletion(n, obj)
+	}
+	if !ok {
+		return
+	}
+	if tokens > a.opts.MaxTokensPerExample {
+		a.rep.TooLong++
+		a.issue(n, Error, "example has %d tokens, more than the %d allowed", tokens, a.opts.MaxTokensPerExample)
+		return
+	}
+	a.rep.Valid++
+
+	canonical, _ := json.Marshal(obj) // map keys are sorted, so equal examples marshal equally
+	sum := sha256.Sum256(canonical)
+	if first, dup := a.seen[sum]; dup {
+		a.rep.Duplicates++
+		a.issue(n, Warning, "duplicate of line %d", first)
+		if !a.opts.KeepDuplicates {
+			return
+		}
+	} else {
+		a.seen[sum] = n
+	}
+
+	a.tokens = append(a.tokens, tokens)
+	a.rep.clean = append(a.rep.clean, append([]byte(nil), line...))
+}
+
+var chatRoles = map[string]bool{
+	openai.RoleSystem:    true,
+	openai.RoleUser:      true,
+	openai.RoleAssistant: true,
+	"tool":               true,
+	"function":           true,
+}
+
+var chatMessageKeys = map[string]bool{
+	"role": true, "content": true, "name": true, "weight": true,
+	"tool_calls": true, "tool_call_id": true, "function_call": true,
+}
+
+func (a *analyzer) chat(n int, obj map[string]json.RawMessage) (int, bool) {
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(obj["messages"], &raw); err != nil {
+		a.issue(n, Error, `"messages" is not an array of objects`)
+		return 0, false
+	}
+	if len(raw) == 0 {
+		a.issue(n, Error, `"messages" is empty`)
+		return 0, false
+	}
+	for k := range obj {
+		if k != "messages" && k != "tools" && k != "functions" && k != "parallel_tool_calls" {
+			a.issue(n, Warning, "unexpected key %q", k)
+		}
+	}
+
+	ok, hasAssistant := true, false
+	messages := make([]openai.Message, 0, len(raw))
+	for i, m := range raw {
+		var msg openai.Message
+		if m["role"] == nil || json.Unmarshal(m["role"], &msg.Role) != nil || msg.Role == "" {
+			a.issue(n, Error, "message %d is missing a role", i)
+			ok = false
+			continue
+		}
+		if !chatRoles[msg.Role] {
+			a.issue(n, Error, "message %d has unknown role %q", i, msg.Role)
+			ok = false
+		}
+		if msg.Role == openai.RoleAssistant {
+			hasAssistant = true
+		}
+
+		hasCalls := m["tool_calls"] != nil || m["function_call"] != nil
+		if c := m["content"]; c == nil || string(c) == "null" {
+			if !(msg.Role == openai.RoleAssistant && hasCalls) {
+				a.issue(n, Error, "message %d is missing content", i)
+				ok = false
+			}
+		} else if err := json.Unmarshal(c, &msg.Content); err != nil {
+			// Content may also be an array of parts; count it as raw JSON.
+			msg.Content = string(c)
+		}
+		if nm := m["name"]; nm != nil {
+			json.Unmarshal(nm, &msg.Name)
+		}
+		for k := range m {
+			if !chatMessageKeys[k] {
+				a.issue(n, Warning, "message %d has unexpected key %q", i, k)
+			}
+		}
+		messages = append(messages, msg)
+	}
+	if !hasAssistant {
+		a.issue(n, Error, "example has no assistant message")
+		ok = false
+	}
+	if !ok {
+		return 0, false
+	}
+
+	tokens, err := openai.CountChatTokens(a.opts.Model, messages)
+	if err != nil {
+		tokens = 3
+		for _, m := range messages {
+			tokens += 3 + a.count(m.Role) + a.count(m.Content) + a.count(m.Name)
+		}
+	}
+	return tokens, true
+}
+
+func (a *analyzer) promptCompletion(n int, obj map[string]json.RawMessage) (int, bool) {
+	var prompt, completion string
+	ok := true
+	if p := obj["prompt"]; p == nil || json.Unmarshal(p, &prompt) != nil {
+		a.issue(n, Error, `"prompt" is missing or not a string`)
+		ok = false
+	}
+	if c := obj["completion"]; c == nil || json.Unmarshal(c, &completion) != nil {
+		a.issue(n, Error, `"completion" is missing or not a string`)
+		ok = false
+	} else if completion == "" {
+		a.issue(n, Error, `"completion" is empty`)
+		ok = false
+	}
+	for k := range obj {
+		if k != "prompt" && k != "completion" {
+			a.issue(n, Warning, "unexpected key %q", k)
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	return a.count(prompt) + a.count(completion), true
+}
+
+// finish computes the token statistics and cost estimate.
+func (a *analyzer) finish(info openai.ModelInfo) {
+	rep := a.rep
+	if len(a.tokens) == 0 {
+		return
+	}
+
+	sorted := append([]int(nil), a.tokens...)
+	sort.Ints(sorted)
+	rep.MinTokens = sorted[0]
+	rep.MaxTokens = sorted[len(sorted)-1]
+	rep.MedianTokens = sorted[len(sorted)/2]
+	for _, t := range sorted {
+		rep.TotalTokens += t
+	}
+
+	rep.TrainingTokensPerEpoch = rep.TotalTokens
+	rep.CostPerEpoch = float64(rep.TrainingTokensPerEpoch) * info.Pricing.Training / 1e6
+	rep.Cost = rep.CostPerEpoch * float64(rep.Epochs)
+}
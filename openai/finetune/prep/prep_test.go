@@ -0,0 +1,47 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package prep
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeTooLongIsNotValid(t *testing.T) {
+	data := strings.Join([]string{
+		`{"messages":[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"}]}`,
+		`{"messages":[{"role":"user","content":"` + strings.Repeat("word ", 100) + `"},{"role":"assistant","content":"ok"}]}`,
+		`{"messages":[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"}]}`,
+	}, "\n")
+	rep, err := Analyze(strings.NewReader(data), Options{MaxTokensPerExample: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Lines != 3 || rep.Valid != 2 || rep.TooLong != 1 || rep.Duplicates != 1 {
+		t.Errorf("lines %d, valid %d, too long %d, duplicates %d, want 3, 2, 1, 1",
+			rep.Lines, rep.Valid, rep.TooLong, rep.Duplicates)
+	}
+	if rep.Kept() != 1 {
+		t.Errorf("kept %d examples, want 1", rep.Kept())
+	}
+}
+
+func TestExampleTokenLimit(t *testing.T) {
+	tests := map[string]int{
+		"gpt-4o-mini":                          65536,
+		"gpt-4o-2024-08-06":                    65536,
+		"gpt-4.1-mini-2025-04-14":              65536,
+		"gpt-3.5-turbo":                        16385,
+		"gpt-3.5-turbo-0125":                   16385,
+		"gpt-3.5-turbo-0613":                   4096,
+		"gpt-4-0613":                           8192,
+		"davinci-002":                          16384,
+		"ft:gpt-4o-mini-2024-07-18:acme::abc1": 65536,
+		"my-model":                             4096,
+	}
+	for model, want := range tests {
+		if got := exampleTokenLimit(model); got != want {
+			t.Errorf("exampleTokenLimit(%q) = %d, want %d", model, got, want)
+		}
+	}
+}
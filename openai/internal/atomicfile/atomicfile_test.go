@@ -0,0 +1,41 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package atomicfile
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	for _, data := range []string{"first", "second"} {
+		if err := WriteFile(path, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(path); string(got) != data {
+			t.Errorf("file holds %q, want %q", got, data)
+		}
+	}
+
+	// A failed write leaves the file as it was and no temporary file behind.
+	failed := errors.New("disk on fire")
+	err := Write(path, func(w io.Writer) error {
+		io.WriteString(w, "thi")
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Errorf("Write() error = %v, want %v", err, failed)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "second" {
+		t.Errorf("file holds %q after a failed write, want %q", got, "second")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory holds %d files, want only %s", len(entries), filepath.Base(path))
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// API endpoints, as listed in ModelInfo.Endpoints.
+const (
+	EndpointChatCompletions     = "/v1/chat/completions"
+	EndpointCompletions         = "/v1/completions"
+	EndpointEdits               = "/v1/edits"
+	EndpointEmbeddings          = "/v1/embeddings"
+	EndpointModerations         = "/v1/moderations"
+	EndpointImageGenerations    = "/v1/images/generations"
+	EndpointImageEdits          = "/v1/images/edits"
+	EndpointImageVariations     = "/v1/images/variations"
+	EndpointAudioTranscriptions = "/v1/audio/transcriptions"
+	EndpointAudioTranslations   = "/v1/audio/translations"
+	EndpointResponses           = "/v1/responses"
+	EndpointAssistants          = "/v1/assistants"
+	EndpointBatches             = "/v1/batches"
+	EndpointFineTuning          = "/v1/fine_tuning/jobs"
+)
+
+// Modalities, as listed in ModelInfo.InputModalities and ModelInfo.OutputModalities.
+const (
+	ModalityText      = "text"
+	ModalityImage     = "image"
+	ModalityAudio     = "audio"
+	ModalityEmbedding = "embedding"
+)
+
+//go:embed models.json
+var embeddedModels []byte
+
+// ModelInfo describes the capabilities and pricing of a model.
+type ModelInfo struct {
+	ID               string   `json:"id"`
+	ContextWindow    int      `json:"context_window,omitempty"`
+	MaxOutputTokens  int      `json:"max_output_tokens,omitempty"`
+	Endpoints        []string `json:"endpoints,omitempty"`
+	InputModalities  []string `json:"input_modalities,omitempty"`
+	OutputModalities []string `json:"output_modalities,omitempty"`
+	SupportsTools    bool     `json:"supports_tools,omitempty"`
+	Pricing          Pricing  `json:"pricing"`
+}
+
+// Pricing holds the price of a model in US dollars per million tokens.
+type Pricing struct {
+	Input       float64 `json:"input,omitempty"`
+	CachedInput float64 `json:"cached_input,omitempty"`
+	Output      float64 `json:"output,omitempty"`
+	Training    float64 `json:"training,omitempty"`
+}
+
+// Cost returns the price in US dollars of a call that used the given numbers of
+// prompt and completion tokens.
+func (p Pricing) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.Input + float64(completionTokens)*p.Output) / 1e6
+}
+
+// Cost returns the price in US dollars of a call with the given usage.
+// Cached prompt tokens are charged at the cached input price when one is set.
+func (mi ModelInfo) Cost(u Usage) float64 {
+	p := mi.Pricing
+	prompt := float64(u.PromptTokens)
+	cost := 0.0
+	if u.PromptTokensDetails != nil && p.CachedInput > 0 {
+		cached := float64(u.PromptTokensDetails.CachedTokens)
+		prompt -= cached
+		cost += cached * p.CachedInput
+	}
+	cost += prompt*p.Input + float64(u.CompletionTokens)*p.Output
+	return cost / 1e6
+}
+
+// SupportsEndpoint reports whether the model can be used with endpoint.
+func (mi ModelInfo) SupportsEndpoint(endpoint string) bool {
+	for _, e := range mi.Endpoints {
+		if e == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// ModelRegistry maps model IDs to what is known about them.
+// It is safe for concurrent use.
+type ModelRegistry struct {
+	mu     sync.RWMutex
+	models map[string]ModelInfo
+}
+
+// DefaultModelRegistry is populated from the model table embedded in this package.
+// Entries may be added or overridden with Register and Load.
+var DefaultModelRegistry = mustEmbeddedRegistry()
+
+func mustEmbeddedRegistry() *ModelRegistry {
+	r := NewModelRegistry()
+	if err := r.Load(bytes.NewReader(embeddedModels)); err != nil {
+		panic("openai: invalid embedded model table: " + err.Error())
+	}
+	return r
+}
+
+// NewModelRegistry returns an empty registry.
+func NewModelRegistry() *ModelRegistry {
+	return &ModelRegistry{models: make(map[string]ModelInfo)}
+}
+
+// Register adds info to the registry, replacing any entry with the same ID.
+func (r *ModelRegistry) Register(info ModelInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.models[info.ID] = info
+}
+
+// Load reads a JSON object of model IDs to ModelInfo from rd, in the same
+// format as the embedded table, and registers every entry.
+func (r *ModelRegistry) Load(rd io.Reader) error {
+	var table map[string]ModelInfo
+	if err := json.NewDecoder(rd).Decode(&table); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, info := range table {
+		info.ID = id
+		r.models[id] = info
+	}
+	return nil
+}
+
+// Lookup returns the entry for id. Dated snapshots such as "gpt-4o-2024-08-06"
+// and fine-tuned models such as "ft:gpt-4o-mini-2024-07-18:org::id" resolve to
+// their base model when they have no entry of their own. The returned ID is
+// always id.
+func (r *ModelRegistry) Lookup(id string) (ModelInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if info, ok := r.models[id]; ok {
+		return info, true
+	}
+
+	base := id
+	if strings.HasPrefix(base, "ft:") {
+		base = strings.TrimPrefix(base, "ft:")
+		if i := strings.Index(base, ":"); i >= 0 {
+			base = base[:i]
+		}
+		if info, ok := r.models[base]; ok {
+			info.ID = id
+			return info, true
+		}
+	}
+
+	best := ""
+	for name := range r.models {
+		if strings.HasPrefix(base, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelInfo{}, false
+	}
+	info := r.models[best]
+	info.ID = id
+	return info, true
+}
+
+// Models returns every registered entry ordered by ID.
+func (r *ModelRegistry) Models() []ModelInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]ModelInfo, 0, len(r.models))
+	for _, info := range r.models {
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Validate checks that model is known, can be used with endpoint and that
+// maxTokens does not exceed its output limit. A zero maxTokens is not checked.
+func (r *ModelRegistry) Validate(model, endpoint string, maxTokens int) error {
+	info, ok := r.Lookup(model)
+	if !ok {
+		return fmt.Errorf("openai: unknown model %q", model)
+	}
+	if endpoint != "" && !info.SupportsEndpoint(endpoint) {
+		return fmt.Errorf("openai: model %q does not support %s", model, endpoint)
+	}
+	if maxTokens > 0 && info.MaxOutputTokens > 0 && maxTokens > info.MaxOutputTokens {
+		return fmt.Errorf("openai: max_tokens %d exceeds the %d output tokens of model %q", maxTokens, info.MaxOutputTokens, model)
+	}
+	return nil
+}
+
+// modelRegistry returns the registry the client enriches results from.
+func (c *OpenAIClient) modelRegistry() *ModelRegistry {
+	if c.ModelRegistry != nil {
+		return c.ModelRegistry
+	}
+	return DefaultModelRegistry
+}
@@ -4,26 +4,34 @@ package openai
 
 import (
 	"context"
+	"fmt"
+	"iter"
 	"net/http"
 )
 
 type ModelsAPI Api
 
 type ModelList struct {
-	Data   []Model `json:"data"`
-	Object string  `json:"object"`
+	Data    []Model `json:"data"`
+	Object  string  `json:"object"`
+	HasMore bool    `json:"has_more"`
 }
 
 type Model struct {
 	ID         string      `json:"id"`
 	Object     string      `json:"object"`
+	Created    int64       `json:"created"`
 	OwnedBy    string      `json:"owned_by"`
 	Permission interface{} `json:"permission"`
+
+	// Info is filled in from the client's ModelRegistry and is nil for
+	// models the registry does not know about.
+	Info *ModelInfo `json:"-"`
 }
 
 // RetrieveModel retrieves a model instance, providing basic information about the model such as the owner and permissioning.
 func (m *ModelsAPI) RetrieveModel(ctx context.Context, name string) (*Model, *Response, error) {
-	u := "v1/models" + name
+	u := fmt.Sprintf("v1/models/%s", name)
 	req, err := m.openAIClient.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, nil, err
@@ -36,12 +44,15 @@ func (m *ModelsAPI) RetrieveModel(ctx context.Context, name string) (*Model, *Re
 		return nil, resp, err
 	}
 
+	m.enrich(model)
+
 	return model, resp, nil
 }
 
 // List lists the currently available models, and provides basic information about each one such as the owner and availability.
-func (m *ModelsAPI) List(ctx context.Context) (*ModelList, *Response, error) {
-	u := "v1/models"
+// Passing opts returns one page of them.
+func (m *ModelsAPI) List(ctx context.Context, opts ...*ListOptions) (*ModelList, *Response, error) {
+	u := firstListOptions(opts).encode("v1/models")
 	req, err := m.openAIClient.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, nil, err
@@ -54,5 +65,26 @@ func (m *ModelsAPI) List(ctx context.Context) (*ModelList, *Response, error) {
 		return nil, resp, err
 	}
 
+	for i := range list.Data {
+		m.enrich(&list.Data[i])
+	}
+
 	return list, resp, nil
 }
+
+// ListAll iterates over all models available to you, fetching pages as needed.
+func (m *ModelsAPI) ListAll(ctx context.Context, opts *ListOptions) iter.Seq2[Model, error] {
+	return paginate(ctx, opts, func(ctx context.Context, o *ListOptions) (page[Model], error) {
+		l, _, err := m.List(ctx, o)
+		if err != nil {
+			return page[Model]{}, err
+		}
+		return page[Model]{items: l.Data, hasMore: l.HasMore}, nil
+	}, func(model Model) string { return model.ID })
+}
+
+func (m *ModelsAPI) enrich(model *Model) {
+	if info, ok := m.openAIClient.modelRegistry().Lookup(model.ID); ok {
+		model.Info = &info
+	}
+}
@@ -11,6 +11,9 @@ type ModerationsAPI Api
 
 type ContentModerationInput struct {
 	Input string `json:"input" binding:"required"`
+	// Model defaults to the latest moderation model. Only requests pinning a
+	// snapshot, such as "omni-moderation-2024-09-26", are served from the
+	// client's Cache, since the latest model changes over time.
 	Model string `json:"model,omitempty"`
 }
 
@@ -55,7 +58,7 @@ func (m *ModerationsAPI) CreateModeration(ctx context.Context, cmReq *ContentMod
 
 	mResp := new(TextModerationResponse)
 
-	resp, err := m.openAIClient.Do(ctx, req, mResp)
+	resp, err := m.openAIClient.doCached(ctx, req, cmReq, pinnedModel(cmReq.Model), mResp)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -8,10 +8,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
+	"time"
 )
 
 const (
@@ -26,6 +29,31 @@ type OpenAIClient struct {
 	client  *http.Client
 	BaseURL *url.URL
 
+	// ModelRegistry describes the models the client works with.
+	// When nil, DefaultModelRegistry is used.
+	ModelRegistry *ModelRegistry
+
+	// UsageRecorder, when set, receives the token usage and cost of every call
+	// that reports usage. If it also implements UsageLimiter it is consulted
+	// before each such call is sent.
+	//
+	// Chat, completion, edit and embedding calls are recorded as they return.
+	// Assistant runs report their usage only once they stop, so they are
+	// recorded by PollRun and StreamRun; runs followed with RetrieveRun are
+	// not. RunBatch records each request once its result is collected, at the
+	// regular price of the model although batches are billed at a discount.
+	// Moderations are free and the image, audio and file endpoints report no
+	// tokens, so none of them are recorded or limited.
+	UsageRecorder UsageRecorder
+
+	// Cache, when set, serves repeated deterministic requests without calling
+	// the API. Completions are only cached when their temperature is set to 0
+	// or they pin a seed, and moderations when they pin a model snapshot.
+	// Entries are scoped to the base URL and credentials of the request, and
+	// expire after CacheTTL, or never when it is zero.
+	Cache    Cache
+	CacheTTL time.Duration
+
 	Completions *CompletionsAPI
 	Models      *ModelsAPI
 	Chat        *ChatAPI
@@ -35,10 +63,22 @@ type OpenAIClient struct {
 	File        *FileAPI
 	FineTunes   *FineTunesAPI
 	Moderations *ModerationsAPI
+
+	FineTuningJobs *FineTuningJobsAPI
+	Uploads        *UploadsAPI
+	Batches        *BatchesAPI
+	Assistants     *AssistantsAPI
+	Threads        *ThreadsAPI
+	Messages       *MessagesAPI
+	Runs           *RunsAPI
+	VectorStores   *VectorStoresAPI
 }
 
 type Response struct {
 	*http.Response
+
+	// CacheHit is set when the response was served from the client's Cache.
+	CacheHit bool
 }
 
 func NewClient(httpClient *http.Client) *OpenAIClient {
@@ -88,10 +128,64 @@ func NewClient(httpClient *http.Client) *OpenAIClient {
 		openAIClient: oapiClient,
 	}
 
+	oapiClient.FineTuningJobs = &FineTuningJobsAPI{
+		openAIClient: oapiClient,
+	}
+
+	oapiClient.Uploads = &UploadsAPI{
+		openAIClient: oapiClient,
+	}
+
+	oapiClient.Batches = &BatchesAPI{
+		openAIClient: oapiClient,
+	}
+
+	oapiClient.Assistants = &AssistantsAPI{
+		openAIClient: oapiClient,
+	}
+
+	oapiClient.Threads = &ThreadsAPI{
+		openAIClient: oapiClient,
+	}
+
+	oapiClient.Messages = &MessagesAPI{
+		openAIClient: oapiClient,
+	}
+
+	oapiClient.Runs = &RunsAPI{
+		openAIClient: oapiClient,
+	}
+
+	oapiClient.VectorStores = &VectorStoresAPI{
+		openAIClient: oapiClient,
+	}
+
 	return oapiClient
 }
 
-func (oapiClient *OpenAIClient) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+// RequestOption customizes a request built by NewRequest.
+type RequestOption func(req *http.Request)
+
+// WithHeader sets the header key to value on a request.
+func WithHeader(key, value string) RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set(key, value)
+	}
+}
+
+// Float64 returns a pointer to v, for optional request fields such as
+// ChatRequest.Temperature.
+func Float64(v float64) *float64 {
+	return &v
+}
+
+// String returns a pointer to v, for optional request fields such as
+// ModifyAssistantRequest.Name.
+func String(v string) *string {
+	return &v
+}
+
+func (oapiClient *OpenAIClient) NewRequest(method, urlStr string, body interface{}, opts ...RequestOption) (*http.Request, error) {
 	if !strings.HasSuffix(oapiClient.BaseURL.Path, "/") {
 		return nil, fmt.Errorf("BaseURL must have a trailing slash, but %q does not", oapiClient.BaseURL)
 	}
@@ -120,6 +214,54 @@ func (oapiClient *OpenAIClient) NewRequest(method, urlStr string, body interface
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+authToken)
 
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	return req, nil
+}
+
+// newMultipartRequest creates a multipart/form-data POST request carrying
+// fields and, when r is not nil, a file part named fileField read from r.
+// The body is buffered so the request can be sent again on retry.
+func (oapiClient *OpenAIClient) newMultipartRequest(urlStr string, fields map[string]string, fileField, filename string, r io.Reader) (*http.Request, error) {
+	buf := &bytes.Buffer{}
+	mw := multipart.NewWriter(buf)
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := mw.WriteField(k, fields[k]); err != nil {
+			return nil, err
+		}
+	}
+	if r != nil {
+		fw, err := mw.CreateFormFile(fileField, filename)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(fw, r); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := oapiClient.NewRequest(http.MethodPost, urlStr, nil)
+	if err != nil {
+		return nil, err
+	}
+	body := buf.Bytes()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
 	return req, nil
 }
 
@@ -140,8 +282,88 @@ func newResponse(r *http.Response) *Response {
 	return response
 }
 
-func (c *OpenAIClient) Do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
-	resp, err := c.client.Do(req)
+// APIError is returned when the API answers with a status outside the 2xx range.
+type APIError struct {
+	Response *http.Response `json:"-"`
+
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Param   string `json:"param"`
+	Code    string `json:"code"`
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode())
+	}
+	if e.Response != nil && e.Response.Request != nil {
+		return fmt.Sprintf("%v %v: %d %s", e.Response.Request.Method, sanitizeURL(e.Response.Request.URL), e.Response.StatusCode, msg)
+	}
+	if e.Response == nil {
+		return "openai: " + msg
+	}
+	return fmt.Sprintf("openai: %d %s", e.StatusCode(), msg)
+}
+
+// StatusCode returns the HTTP status of the response that caused the error.
+func (e *APIError) StatusCode() int {
+	if e.Response == nil {
+		return 0
+	}
+	return e.Response.StatusCode
+}
+
+// Temporary reports whether the request may succeed if retried: rate limits,
+// timeouts and server errors, but not an exhausted quota.
+func (e *APIError) Temporary() bool {
+	switch code := e.StatusCode(); {
+	case code == http.StatusTooManyRequests:
+		return e.Code != "insufficient_quota" && e.Type != "insufficient_quota"
+	case code == http.StatusRequestTimeout:
+		return true
+	default:
+		return code >= 500
+	}
+}
+
+// CheckResponse returns an *APIError when r has a status outside the 2xx range.
+// The error body, if any, is read to fill in the message.
+func CheckResponse(r *http.Response) error {
+	if c := r.StatusCode; 200 <= c && c <= 299 {
+		return nil
+	}
+
+	apiErr := &APIError{Response: r}
+	data, err := io.ReadAll(r.Body)
+	if err == nil && len(data) > 0 {
+		var body struct {
+			Error struct {
+				Message string      `json:"message"`
+				Type    string      `json:"type"`
+				Param   interface{} `json:"param"`
+				Code    interface{} `json:"code"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &body) == nil {
+			apiErr.Message = body.Error.Message
+			apiErr.Type = body.Error.Type
+			if body.Error.Param != nil {
+				apiErr.Param = fmt.Sprint(body.Error.Param)
+			}
+			if body.Error.Code != nil {
+				apiErr.Code = fmt.Sprint(body.Error.Code)
+			}
+		} else {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+	}
+	return apiErr
+}
+
+// send performs req with ctx attached, without reading the response body.
+func (c *OpenAIClient) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	resp, err := c.client.Do(req.WithContext(ctx))
 	if err != nil {
 		// If we got an error, and the context has been canceled,
 		// the context's error is probably more useful.
@@ -161,11 +383,44 @@ func (c *OpenAIClient) Do(ctx context.Context, req *http.Request, v interface{})
 
 		return nil, err
 	}
+	return resp, nil
+}
+
+// doStream sends req and returns the response with its body left open for the
+// caller to read and close. Responses outside the 2xx range are returned as
+// an *APIError with the body already closed.
+func (c *OpenAIClient) doStream(ctx context.Context, req *http.Request) (*Response, error) {
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newResponse(resp)
+	if err := CheckResponse(resp); err != nil {
+		resp.Body.Close()
+		return response, err
+	}
+	return response, nil
+}
+
+// Do sends req and decodes the JSON response body into v, or copies it to v
+// when v is an io.Writer. A response outside the 2xx range is returned as an
+// *APIError carrying the error the API reported; v is left untouched.
+func (c *OpenAIClient) Do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
 
 	defer resp.Body.Close()
 
 	response := newResponse(resp)
 
+	if err := CheckResponse(resp); err != nil {
+		return response, err
+	}
+
 	switch v := v.(type) {
 	case nil:
 	case io.Writer:
@@ -0,0 +1,103 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newTestClient returns a client that sends its requests to a test server
+// answering them with handler. The server is closed when the test ends.
+func newTestClient(t *testing.T, handler http.Handler) *OpenAIClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c := NewClient(nil)
+	c.BaseURL, _ = url.Parse(srv.URL + "/")
+	return c
+}
+
+// writeAPIError answers with status and an error body as the API sends it.
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"error":{"message":%q,"type":"invalid_request_error"}}`, message)
+}
+
+func TestDoReturnsAPIError(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		wantMessage   string
+		wantCode      string
+		wantTemporary bool
+	}{
+		{
+			name:        "invalid request",
+			status:      http.StatusBadRequest,
+			body:        `{"error":{"message":"too long","type":"invalid_request_error","param":"messages","code":"context_length_exceeded"}}`,
+			wantMessage: "too long",
+			wantCode:    "context_length_exceeded",
+		},
+		{
+			name:          "rate limited",
+			status:        http.StatusTooManyRequests,
+			body:          `{"error":{"message":"slow down","type":"requests","code":"rate_limit_exceeded"}}`,
+			wantMessage:   "slow down",
+			wantCode:      "rate_limit_exceeded",
+			wantTemporary: true,
+		},
+		{
+			name:        "quota exhausted",
+			status:      http.StatusTooManyRequests,
+			body:        `{"error":{"message":"no credit","type":"insufficient_quota","code":"insufficient_quota"}}`,
+			wantMessage: "no credit",
+			wantCode:    "insufficient_quota",
+		},
+		{
+			name:          "plain text body",
+			status:        http.StatusBadGateway,
+			body:          "upstream down\n",
+			wantMessage:   "upstream down",
+			wantTemporary: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+
+			req, err := c.NewRequest(http.MethodGet, "v1/models", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out map[string]interface{}
+			resp, err := c.Do(context.Background(), req, &out)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want an *APIError", err)
+			}
+			if resp == nil || resp.StatusCode != tt.status || apiErr.StatusCode() != tt.status {
+				t.Errorf("status = %d, want %d", apiErr.StatusCode(), tt.status)
+			}
+			if apiErr.Message != tt.wantMessage || apiErr.Code != tt.wantCode {
+				t.Errorf("got message %q code %q, want %q %q", apiErr.Message, apiErr.Code, tt.wantMessage, tt.wantCode)
+			}
+			if apiErr.Temporary() != tt.wantTemporary {
+				t.Errorf("Temporary() = %v, want %v", apiErr.Temporary(), tt.wantTemporary)
+			}
+			if out != nil {
+				t.Errorf("error body was decoded into the result: %v", out)
+			}
+		})
+	}
+}
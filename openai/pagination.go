@@ -0,0 +1,113 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// Sort orders accepted by ListOptions.Order.
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// ListOptions page through a cursor-paginated list. Endpoints that do not
+// paginate ignore them.
+type ListOptions struct {
+	// After is the ID of the last item of the previous page.
+	After string
+	// Limit is the number of items per page. The API defaults to 20.
+	Limit int
+	// Order sorts by creation time, OrderAsc or OrderDesc.
+	Order string
+}
+
+func (o *ListOptions) encode(u string) string {
+	if o == nil {
+		return u
+	}
+	q := url.Values{}
+	if o.After != "" {
+		q.Set("after", o.After)
+	}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Order != "" {
+		q.Set("order", o.Order)
+	}
+	if len(q) == 0 {
+		return u
+	}
+	return u + "?" + q.Encode()
+}
+
+// firstListOptions returns the options passed to a List method that takes
+// them as an optional trailing argument, or nil when none were passed.
+func firstListOptions(opts []*ListOptions) *ListOptions {
+	if len(opts) == 0 {
+		return nil
+	}
+	return opts[0]
+}
+
+// page is what paginate needs to know about one page of a list.
+type page[T any] struct {
+	items   []T
+	hasMore bool
+	// lastID is the cursor of the next page; the ID of the last item is used
+	// when the API does not report it.
+	lastID string
+}
+
+// paginate returns an iterator over every item of a cursor-paginated list,
+// starting at opts and fetching pages until the API reports there are no
+// more. Iteration stops at the first error, which is yielded with a zero item.
+func paginate[T any](ctx context.Context, opts *ListOptions, fetch func(context.Context, *ListOptions) (page[T], error), id func(T) string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var o ListOptions
+		if opts != nil {
+			o = *opts
+		}
+		for {
+			p, err := fetch(ctx, &o)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range p.items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if !p.hasMore || len(p.items) == 0 {
+				return
+			}
+			next := p.lastID
+			if next == "" {
+				next = id(p.items[len(p.items)-1])
+			}
+			if next == o.After {
+				return // the API is not moving forward
+			}
+			o.After = next
+		}
+	}
+}
+
+// CollectAll gathers the items of a paginated iterator, stopping at the first error.
+func CollectAll[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var all []T
+	for item, err := range seq {
+		if err != nil {
+			return all, err
+		}
+		all = append(all, item)
+	}
+	return all, nil
+}
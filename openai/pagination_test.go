@@ -0,0 +1,52 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestListOptionsAreOptional(t *testing.T) {
+	var queries []string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		fmt.Fprint(w, `{"object":"list","data":[]}`)
+	}))
+	ctx := context.Background()
+
+	if _, _, err := c.File.List(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Models.List(ctx, &ListOptions{Limit: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.FineTunes.List(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"", "limit=2", ""}; fmt.Sprint(queries) != fmt.Sprint(want) {
+		t.Errorf("queries = %q, want %q", queries, want)
+	}
+}
+
+func TestListAllFollowsCursor(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch after := r.URL.Query().Get("after"); after {
+		case "":
+			fmt.Fprint(w, `{"object":"list","data":[{"id":"file-1"},{"id":"file-2"}],"has_more":true}`)
+		case "file-2":
+			fmt.Fprint(w, `{"object":"list","data":[{"id":"file-3"}],"has_more":false}`)
+		default:
+			t.Errorf("unexpected cursor %q", after)
+		}
+	}))
+	files, err := CollectAll(c.File.ListAll(context.Background(), &ListOptions{Limit: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 || files[2].ID != "file-3" {
+		t.Errorf("files = %+v, want file-1 to file-3", files)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+// Package rag answers questions with a chat model grounded in documents
+// retrieved by embedding similarity.
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AGMETEOR/openai-go/openai"
+	"github.com/AGMETEOR/openai-go/openai/embeddings/index"
+	"github.com/AGMETEOR/openai-go/openai/tokenizer"
+)
+
+// Document is a retrieved piece of text.
+type Document struct {
+	ID       string
+	Text     string
+	Score    float32
+	Metadata map[string]string
+}
+
+// Retriever returns the k documents closest to an embedded query, best first.
+type Retriever interface {
+	Retrieve(ctx context.Context, query []float32, k int) ([]Document, error)
+}
+
+// TextKey is the metadata key IndexRetriever reads document text from.
+const TextKey = "text"
+
+// IndexRetriever retrieves documents from an index.Index whose entries keep
+// their text in the TextKey metadata field.
+type IndexRetriever struct {
+	Index *index.Index
+	// Filter, when set, restricts the entries that can be retrieved.
+	Filter index.Filter
+}
+
+// Retrieve implements Retriever.
+func (r IndexRetriever) Retrieve(ctx context.Context, query []float32, k int) ([]Document, error) {
+	results, err := r.Index.Search(query, k, r.Filter)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]Document, len(results))
+	for i, res := range results {
+		docs[i] = Document{
+			ID:       res.ID,
+			Text:     res.Metadata[TextKey],
+			Score:    res.Score,
+			Metadata: res.Metadata,
+		}
+	}
+	return docs, nil
+}
+
+// DefaultSystemPrompt instructs the model to answer from the numbered sources only.
+const DefaultSystemPrompt = "Answer the question using only the numbered sources below. " +
+	"Cite the sources you use with their number in square brackets, for example [1]. " +
+	"If the sources do not contain the answer, say that you do not know."
+
+// Pipeline embeds a question, retrieves documents for it and asks a chat
+// model to answer from them.
+type Pipeline struct {
+	Client         *openai.OpenAIClient
+	Retriever      Retriever
+	EmbeddingModel string
+	ChatModel      string
+
+	// TopK is the number of documents retrieved. Defaults to 4.
+	TopK int
+	// MinScore, when set, drops retrieved documents scoring below it. Scores
+	// depend on the index metric: cosine similarity is between -1 and 1,
+	// OpenAI embeddings being normalized dot products are too, and L2 scores
+	// are negated distances, so never above 0.
+	MinScore *float32
+	// MaxContextTokens, when set, drops the lowest ranked documents until the
+	// sources take up no more than this many tokens. Tokens are estimated when
+	// the tokenizer does not know ChatModel.
+	MaxContextTokens int
+
+	// SystemPrompt defaults to DefaultSystemPrompt.
+	SystemPrompt string
+	MaxTokens    int
+	// Temperature is left to the API's default when nil.
+	Temperature *float64
+	User        string
+}
+
+// Answer is the model's reply together with the documents behind it.
+type Answer struct {
+	Text string
+	// Sources are the IDs of the documents the answer cites, in the order they
+	// were retrieved. If the answer cites none, all retrieved documents are listed.
+	Sources []string
+	// Documents are all documents that were given to the model.
+	Documents  []Document
+	Completion *openai.ChatCompletion
+}
+
+// ErrNoDocuments is returned when retrieval finds nothing to ground an answer on.
+var ErrNoDocuments = errors.New("rag: no documents retrieved")
+
+// BuildRequest embeds query, retrieves documents and returns the chat request
+// that asks the model to answer from them, along with those documents.
+func (p *Pipeline) BuildRequest(ctx context.Context, query string) (*openai.ChatRequest, []Document, error) {
+	embResp, _, err := p.Client.Embeddings.CreateEmbeddings(ctx, &openai.EmbeddingRequest{
+		Model: p.EmbeddingModel,
+		Input: openai.EmbeddingText(query),
+		User:  p.User,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("rag: embedding query: %w", err)
+	}
+	if len(embResp.Data) == 0 {
+		return nil, nil, errors.New("rag: embedding query returned no vector")
+	}
+
+	k := p.TopK
+	if k <= 0 {
+		k = 4
+	}
+	docs, err := p.Retriever.Retrieve(ctx, embResp.Data[0].Embedding, k)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rag: retrieving documents: %w", err)
+	}
+	docs = p.selectDocuments(docs)
+	if len(docs) == 0 {
+		return nil, nil, ErrNoDocuments
+	}
+
+	system := p.SystemPrompt
+	if system == "" {
+		system = DefaultSystemPrompt
+	}
+
+	chatReq := &openai.ChatRequest{
+		Model: p.ChatModel,
+		Messages: []openai.Message{
+			{Role: openai.RoleSystem, Content: system + "\n\n" + formatSources(docs)},
+			{Role: openai.RoleUser, Content: query},
+		},
+		MaxTokens:   p.MaxTokens,
+		Temperature: p.Temperature,
+		User:        p.User,
+	}
+	return chatReq, docs, nil
+}
+
+// Ask answers query from the retrieved documents.
+func (p *Pipeline) Ask(ctx context.Context, query string) (*Answer, error) {
+	chatReq, docs, err := p.BuildRequest(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	completion, _, err := p.Client.Chat.CreateChatCompletion(ctx, chatReq)
+	if err != nil {
+		return nil, err
+	}
+	if len(completion.Choices) == 0 {
+		return nil, errors.New("rag: chat completion returned no choices")
+	}
+
+	text := completion.Choices[0].Message.Content
+	return &Answer{
+		Text:       text,
+		Sources:    citedSources(text, docs),
+		Documents:  docs,
+		Completion: completion,
+	}, nil
+}
+
+// selectDocuments applies MinScore and MaxContextTokens.
+func (p *Pipeline) selectDocuments(docs []Document) []Document {
+	out := docs[:0:0]
+	for _, d := range docs {
+		if p.MinScore == nil || d.Score >= *p.MinScore {
+			out = append(out, d)
+		}
+	}
+	if p.MaxContextTokens <= 0 {
+		return out
+	}
+
+	count := tokenizer.CounterForModelOrEstimate(p.ChatModel)
+	for len(out) > 0 && count(formatSources(out)) > p.MaxContextTokens {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// formatSources numbers the documents from 1 for the model to cite.
+func formatSources(docs []Document) string {
+	var b strings.Builder
+	b.WriteString("Sources:\n")
+	for i, d := range docs {
+		fmt.Fprintf(&b, "\n[%d] %s\n", i+1, strings.TrimSpace(d.Text))
+	}
+	return b.String()
+}
+
+var citation = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// citedSources returns the IDs of the documents cited as [n] in text.
+func citedSources(text string, docs []Document) []string {
+	cited := make(map[int]bool)
+	for _, m := range citation.FindAllStringSubmatch(text, -1) {
+		for _, part := range strings.Split(m[1], ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err == nil && n >= 1 && n <= len(docs) {
+				cited[n-1] = true
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(docs))
+	for i, d := range docs {
+		if len(cited) == 0 || cited[i] {
+			ids = append(ids, d.ID)
+		}
+	}
+	return ids
+}
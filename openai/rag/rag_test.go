@@ -0,0 +1,51 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package rag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectDocumentsMinScore(t *testing.T) {
+	// Scores as an L2 index reports them: negated distances.
+	docs := []Document{
+		{ID: "a", Text: "alpha", Score: -0.2},
+		{ID: "b", Text: "beta", Score: -0.9},
+		{ID: "c", Text: "gamma", Score: -1.4},
+	}
+	threshold := float32(-1)
+	tests := []struct {
+		name     string
+		minScore *float32
+		want     []string
+	}{
+		{"unset keeps everything", nil, []string{"a", "b", "c"}},
+		{"set drops lower scores", &threshold, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		p := &Pipeline{MinScore: tt.minScore}
+		var got []string
+		for _, d := range p.selectDocuments(docs) {
+			got = append(got, d.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSelectDocumentsMaxContextTokens(t *testing.T) {
+	docs := []Document{
+		{ID: "a", Text: "the first source", Score: 0.9},
+		{ID: "b", Text: "the second source", Score: 0.8},
+	}
+	full := (&Pipeline{ChatModel: "gpt-4o"}).selectDocuments(docs)
+	if len(full) != 2 {
+		t.Fatalf("got %d documents without a limit, want 2", len(full))
+	}
+	p := &Pipeline{ChatModel: "gpt-4o", MaxContextTokens: 10}
+	if got := p.selectDocuments(docs); len(got) != 1 || got[0].ID != "a" {
+		t.Errorf("got %+v, want only the best document", got)
+	}
+}
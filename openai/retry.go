@@ -0,0 +1,57 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"strconv"
+	"time"
+)
+
+// isRetryable reports whether err is worth retrying: a temporary API error or
+// a network error, but never a canceled context.
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryDelay returns how long to wait before retry number attempt (starting at 0).
+// A Retry-After header on an API error takes precedence over the exponential
+// backoff, which starts at half a second, doubles each time and is capped at 30s.
+func retryDelay(attempt int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Response != nil {
+		if s, perr := strconv.Atoi(apiErr.Response.Header.Get("Retry-After")); perr == nil && s > 0 {
+			return time.Duration(s) * time.Second
+		}
+	}
+
+	d := 500 * time.Millisecond << uint(attempt)
+	if d <= 0 || d > 30*time.Second {
+		d = 30 * time.Second
+	}
+	// Add up to 25% jitter so concurrent callers spread out.
+	return d + time.Duration(rand.Int63n(int64(d)/4+1))
+}
+
+// sleepCtx waits for d or until ctx is done, whichever comes first.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
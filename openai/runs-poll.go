@@ -0,0 +1,126 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ToolCallHandler computes the output of a function tool call made by a run.
+type ToolCallHandler func(ctx context.Context, call ToolCall) (string, error)
+
+// PollRunOptions configures PollRun. The zero value is usable.
+type PollRunOptions struct {
+	// Interval is the delay between polls. Defaults to one second.
+	Interval time.Duration
+	// HandleToolCall, when set, answers the function calls of a run that
+	// requires action; PollRun submits the outputs and keeps waiting. Without
+	// it, PollRun returns the run in the requires_action status.
+	HandleToolCall ToolCallHandler
+	// OnStatus, when set, is called with every state of the run fetched.
+	OnStatus func(*Run)
+}
+
+// PollRun waits while the run is queued, in progress or cancelling, and
+// returns it once it stops. A failed or expired run is returned together with
+// a *RunError.
+func (r *RunsAPI) PollRun(ctx context.Context, threadID, runID string, opts *PollRunOptions) (*Run, error) {
+	if opts == nil {
+		opts = &PollRunOptions{}
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	for attempt := 0; ; {
+		run, _, err := r.RetrieveRun(ctx, threadID, runID)
+		wait := interval
+		if err != nil {
+			if !isRetryable(err) {
+				return nil, err
+			}
+			wait = retryDelay(attempt, err)
+			attempt++
+		} else {
+			if opts.OnStatus != nil {
+				opts.OnStatus(run)
+			}
+			switch run.Status {
+			case RunStatusQueued, RunStatusInProgress, RunStatusCancelling:
+			case RunStatusRequiresAction:
+				if opts.HandleToolCall == nil {
+					return run, nil
+				}
+				if err := r.handleRequiredAction(ctx, run, opts.HandleToolCall); err != nil {
+					return run, err
+				}
+				continue
+			default:
+				r.recordRunUsage(ctx, run)
+				return run, runFailure(run)
+			}
+		}
+
+		if err := sleepCtx(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// handleRequiredAction runs handle on every tool call run is waiting for and
+// submits the outputs, retrying temporary failures with backoff.
+func (r *RunsAPI) handleRequiredAction(ctx context.Context, run *Run, handle ToolCallHandler) error {
+	outputs, err := toolOutputs(ctx, run, handle)
+	if err != nil {
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		_, _, err := r.SubmitToolOutputs(ctx, run.ThreadID, run.ID, outputs)
+		if !isRetryable(err) {
+			return err
+		}
+		if err := sleepCtx(ctx, retryDelay(attempt, err)); err != nil {
+			return err
+		}
+	}
+}
+
+// toolOutputs runs handle on every tool call run is waiting for.
+func toolOutputs(ctx context.Context, run *Run, handle ToolCallHandler) ([]ToolOutput, error) {
+	if run.RequiredAction == nil {
+		return nil, errors.New("openai: run requires action but lists none")
+	}
+	calls := run.RequiredAction.SubmitToolOutputs.ToolCalls
+	outputs := make([]ToolOutput, 0, len(calls))
+	for _, call := range calls {
+		out, err := handle(ctx, call)
+		if err != nil {
+			name := call.Type
+			if call.Function != nil {
+				name = call.Function.Name
+			}
+			return nil, fmt.Errorf("openai: tool call %s (%s): %w", call.ID, name, err)
+		}
+		outputs = append(outputs, ToolOutput{ToolCallID: call.ID, Output: out})
+	}
+	return outputs, nil
+}
+
+// runFailure returns the error of a run that stopped without completing, or
+// nil if it completed, was cancelled or stopped at a token limit.
+func runFailure(run *Run) error {
+	switch run.Status {
+	case RunStatusFailed:
+		if run.LastError != nil {
+			return run.LastError
+		}
+		return &RunError{Code: RunStatusFailed, Message: "run " + run.ID + " failed"}
+	case RunStatusExpired:
+		return &RunError{Code: RunStatusExpired, Message: "run " + run.ID + " expired before it completed"}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pollServer serves run_1, which goes through statuses one retrieval at a
+// time. When it reaches requires_action it waits there for the outputs of
+// call_1, and the first submitFailures submissions fail with a 503.
+type pollServer struct {
+	mu             sync.Mutex
+	statuses       []string
+	final          Run
+	submitFailures int
+	submitted      [][]ToolOutput
+	attempts       int
+}
+
+func (s *pollServer) run() Run {
+	if len(s.statuses) == 0 {
+		return s.final
+	}
+	run := Run{ID: "run_1", ThreadID: "thread_1", Status: s.statuses[0]}
+	if run.Status == RunStatusRequiresAction {
+		run.RequiredAction = &RequiredAction{Type: "submit_tool_outputs"}
+		run.RequiredAction.SubmitToolOutputs.ToolCalls = []ToolCall{
+			{ID: "call_1", Type: ToolTypeFunction, Function: &FunctionCall{Name: "weather", Arguments: "{}"}},
+		}
+	} else {
+		s.statuses = s.statuses[1:]
+	}
+	return run
+}
+
+func (s *pollServer) client(t *testing.T) *OpenAIClient {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/threads/thread_1/runs/run_1", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		json.NewEncoder(w).Encode(s.run())
+	})
+	mux.HandleFunc("POST /v1/threads/thread_1/runs/run_1/submit_tool_outputs", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.attempts++
+		if s.attempts <= s.submitFailures {
+			writeAPIError(w, http.StatusServiceUnavailable, "overloaded")
+			return
+		}
+		if len(s.statuses) == 0 || s.statuses[0] != RunStatusRequiresAction {
+			writeAPIError(w, http.StatusBadRequest, "run does not require action")
+			return
+		}
+		var body struct {
+			ToolOutputs []ToolOutput `json:"tool_outputs"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		s.submitted = append(s.submitted, body.ToolOutputs)
+		s.statuses = s.statuses[1:]
+		json.NewEncoder(w).Encode(Run{ID: "run_1", ThreadID: "thread_1", Status: RunStatusQueued})
+	})
+	return newTestClient(t, mux)
+}
+
+func TestPollRunTerminalStatuses(t *testing.T) {
+	usage := &Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}
+	tests := []struct {
+		name     string
+		final    Run
+		wantCode string // of the *RunError returned, if any
+	}{
+		{"completed", Run{Status: RunStatusCompleted, Usage: usage}, ""},
+		{"incomplete", Run{Status: RunStatusIncomplete, Usage: usage}, ""},
+		{"cancelled", Run{Status: RunStatusCancelled}, ""},
+		{"failed with an error", Run{Status: RunStatusFailed, LastError: &RunError{Code: "rate_limit_exceeded", Message: "slow down"}, Usage: usage}, "rate_limit_exceeded"},
+		{"failed without an error", Run{Status: RunStatusFailed}, RunStatusFailed},
+		{"expired", Run{Status: RunStatusExpired}, RunStatusExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.final.ID, tt.final.ThreadID, tt.final.Model = "run_1", "thread_1", "gpt-4o"
+			s := &pollServer{statuses: []string{RunStatusQueued, RunStatusInProgress, RunStatusCancelling}, final: tt.final}
+			c := s.client(t)
+			tracker := NewUsageTracker()
+			c.UsageRecorder = tracker
+
+			var seen []string
+			run, err := c.Runs.PollRun(context.Background(), "thread_1", "run_1", &PollRunOptions{
+				Interval: time.Millisecond,
+				OnStatus: func(r *Run) { seen = append(seen, r.Status) },
+			})
+
+			var runErr *RunError
+			switch {
+			case tt.wantCode == "" && err != nil:
+				t.Errorf("err = %v, want nil", err)
+			case tt.wantCode != "" && (!errors.As(err, &runErr) || runErr.Code != tt.wantCode):
+				t.Errorf("err = %v, want a *RunError with code %q", err, tt.wantCode)
+			}
+			if run == nil || run.Status != tt.final.Status {
+				t.Fatalf("run = %+v, want status %q", run, tt.final.Status)
+			}
+			want := fmt.Sprint([]string{RunStatusQueued, RunStatusInProgress, RunStatusCancelling, tt.final.Status})
+			if fmt.Sprint(seen) != want {
+				t.Errorf("statuses seen = %v, want %v", seen, want)
+			}
+			wantCalls := 0
+			if tt.final.Usage != nil {
+				wantCalls = 1
+			}
+			if got := tracker.Total(); got.Calls != wantCalls || got.Calls == 1 && got.TotalTokens != 15 {
+				t.Errorf("usage recorded = %+v, want %d calls", got, wantCalls)
+			}
+		})
+	}
+}
+
+func TestPollRunRequiresAction(t *testing.T) {
+	newServer := func(submitFailures int) *pollServer {
+		return &pollServer{
+			statuses:       []string{RunStatusInProgress, RunStatusRequiresAction, RunStatusInProgress},
+			final:          Run{ID: "run_1", ThreadID: "thread_1", Status: RunStatusCompleted},
+			submitFailures: submitFailures,
+		}
+	}
+	opts := &PollRunOptions{Interval: time.Millisecond, HandleToolCall: weatherTool}
+
+	t.Run("without a handler", func(t *testing.T) {
+		s := newServer(0)
+		run, err := s.client(t).Runs.PollRun(context.Background(), "thread_1", "run_1", &PollRunOptions{Interval: time.Millisecond})
+		if err != nil || run.Status != RunStatusRequiresAction {
+			t.Errorf("PollRun() = %+v, %v, want the run requiring action", run, err)
+		}
+		if len(s.submitted) != 0 {
+			t.Errorf("submitted %v without a handler", s.submitted)
+		}
+	})
+
+	t.Run("submits outputs", func(t *testing.T) {
+		s := newServer(0)
+		run, err := s.client(t).Runs.PollRun(context.Background(), "thread_1", "run_1", opts)
+		if err != nil || run.Status != RunStatusCompleted {
+			t.Fatalf("PollRun() = %+v, %v, want the completed run", run, err)
+		}
+		if want := "[[{call_1 sunny}]]"; fmt.Sprint(s.submitted) != want {
+			t.Errorf("submitted %v, want %s", s.submitted, want)
+		}
+	})
+
+	t.Run("retries a temporary submit failure", func(t *testing.T) {
+		s := newServer(1)
+		run, err := s.client(t).Runs.PollRun(context.Background(), "thread_1", "run_1", opts)
+		if err != nil || run.Status != RunStatusCompleted {
+			t.Fatalf("PollRun() = %+v, %v, want the completed run", run, err)
+		}
+		if s.attempts != 2 || len(s.submitted) != 1 {
+			t.Errorf("%d submit attempts, %d accepted; want 2 and 1", s.attempts, len(s.submitted))
+		}
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		s := newServer(0)
+		failing := func(ctx context.Context, call ToolCall) (string, error) {
+			return "", errors.New("no forecast")
+		}
+		run, err := s.client(t).Runs.PollRun(context.Background(), "thread_1", "run_1", &PollRunOptions{Interval: time.Millisecond, HandleToolCall: failing})
+		if err == nil || run == nil || run.Status != RunStatusRequiresAction {
+			t.Errorf("PollRun() = %+v, %v, want the run requiring action and the handler's error", run, err)
+		}
+		if s.attempts != 0 {
+			t.Errorf("%d submit attempts after the handler failed", s.attempts)
+		}
+	})
+}
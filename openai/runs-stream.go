@@ -0,0 +1,323 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Events streamed by assistant runs.
+const (
+	EventThreadCreated     = "thread.created"
+	EventRunCreated        = "thread.run.created"
+	EventRunQueued         = "thread.run.queued"
+	EventRunInProgress     = "thread.run.in_progress"
+	EventRunRequiresAction = "thread.run.requires_action"
+	EventRunCompleted      = "thread.run.completed"
+	EventRunIncomplete     = "thread.run.incomplete"
+	EventRunFailed         = "thread.run.failed"
+	EventRunCancelling     = "thread.run.cancelling"
+	EventRunCancelled      = "thread.run.cancelled"
+	EventRunExpired        = "thread.run.expired"
+	EventRunStepCreated    = "thread.run.step.created"
+	EventRunStepInProgress = "thread.run.step.in_progress"
+	EventRunStepDelta      = "thread.run.step.delta"
+	EventRunStepCompleted  = "thread.run.step.completed"
+	EventRunStepFailed     = "thread.run.step.failed"
+	EventRunStepCancelled  = "thread.run.step.cancelled"
+	EventRunStepExpired    = "thread.run.step.expired"
+	EventMessageCreated    = "thread.message.created"
+	EventMessageInProgress = "thread.message.in_progress"
+	EventMessageDelta      = "thread.message.delta"
+	EventMessageCompleted  = "thread.message.completed"
+	EventMessageIncomplete = "thread.message.incomplete"
+	EventError             = "error"
+	EventDone              = "done"
+)
+
+// MessageDelta is a change to a message being written.
+type MessageDelta struct {
+	ID     string `json:"id"`
+	Object string `json:"object"`
+	Delta  struct {
+		Role    string                `json:"role"`
+		Content []MessageContentDelta `json:"content"`
+	} `json:"delta"`
+}
+
+// MessageContentDelta is a change to the content part at Index.
+type MessageContentDelta struct {
+	Index int `json:"index"`
+	MessageContent
+}
+
+// Text returns the text added by the delta.
+func (d *MessageDelta) Text() string {
+	var sb strings.Builder
+	for _, c := range d.Delta.Content {
+		if c.Type == MessageContentText && c.Text != nil {
+			sb.WriteString(c.Text.Value)
+		}
+	}
+	return sb.String()
+}
+
+// RunStepDelta is a change to a run step in progress.
+type RunStepDelta struct {
+	ID     string `json:"id"`
+	Object string `json:"object"`
+	Delta  struct {
+		StepDetails struct {
+			Type            string `json:"type"`
+			MessageCreation *struct {
+				MessageID string `json:"message_id"`
+			} `json:"message_creation,omitempty"`
+			ToolCalls []ToolCallDelta `json:"tool_calls,omitempty"`
+		} `json:"step_details"`
+	} `json:"delta"`
+}
+
+// ToolCallDelta is a change to the tool call at Index.
+type ToolCallDelta struct {
+	Index int `json:"index"`
+	ToolCall
+}
+
+// AssistantEvent is an event of a run stream. Event names it, and the field
+// matching its kind is set.
+type AssistantEvent struct {
+	Event string
+
+	Thread       *Thread
+	Run          *Run
+	RunStep      *RunStep
+	RunStepDelta *RunStepDelta
+	Message      *ThreadMessage
+	MessageDelta *MessageDelta
+	Error        *APIError
+}
+
+// AssistantEventHandler receives the events of a run stream. Returning an
+// error from a callback stops the stream. Embed NopAssistantEventHandler to
+// implement only the callbacks needed.
+type AssistantEventHandler interface {
+	OnThreadCreated(thread *Thread) error
+	// OnRun is called for every thread.run.* event.
+	OnRun(event string, run *Run) error
+	// OnRunStep is called for every thread.run.step.* event but deltas.
+	OnRunStep(event string, step *RunStep) error
+	OnRunStepDelta(delta *RunStepDelta) error
+	// OnMessage is called for every thread.message.* event but deltas.
+	OnMessage(event string, msg *ThreadMessage) error
+	OnMessageDelta(delta *MessageDelta) error
+	// OnError is called for an error event. The stream ends after it.
+	OnError(err *APIError) error
+}
+
+// NopAssistantEventHandler ignores every event.
+type NopAssistantEventHandler struct{}
+
+func (NopAssistantEventHandler) OnThreadCreated(*Thread) error          { return nil }
+func (NopAssistantEventHandler) OnRun(string, *Run) error               { return nil }
+func (NopAssistantEventHandler) OnRunStep(string, *RunStep) error       { return nil }
+func (NopAssistantEventHandler) OnRunStepDelta(*RunStepDelta) error     { return nil }
+func (NopAssistantEventHandler) OnMessage(string, *ThreadMessage) error { return nil }
+func (NopAssistantEventHandler) OnMessageDelta(*MessageDelta) error     { return nil }
+func (NopAssistantEventHandler) OnError(*APIError) error                { return nil }
+
+// AssistantStream reads the events of a run stream. It must be closed.
+type AssistantStream struct {
+	body io.ReadCloser
+	dec  *sseDecoder
+}
+
+// Next returns the next event, or io.EOF once the stream is done.
+func (s *AssistantStream) Next() (*AssistantEvent, error) {
+	for {
+		ev, err := s.dec.Next()
+		if err != nil {
+			return nil, err
+		}
+		if ev.Event == EventDone || string(ev.Data) == sseDone {
+			return nil, io.EOF
+		}
+
+		out := &AssistantEvent{Event: ev.Event}
+		var target interface{}
+		switch {
+		case ev.Event == EventThreadCreated:
+			out.Thread = new(Thread)
+			target = out.Thread
+		case ev.Event == EventRunStepDelta:
+			out.RunStepDelta = new(RunStepDelta)
+			target = out.RunStepDelta
+		case strings.HasPrefix(ev.Event, "thread.run.step."):
+			out.RunStep = new(RunStep)
+			target = out.RunStep
+		case strings.HasPrefix(ev.Event, "thread.run."):
+			out.Run = new(Run)
+			target = out.Run
+		case ev.Event == EventMessageDelta:
+			out.MessageDelta = new(MessageDelta)
+			target = out.MessageDelta
+		case strings.HasPrefix(ev.Event, "thread.message."):
+			out.Message = new(ThreadMessage)
+			target = out.Message
+		case ev.Event == EventError:
+			out.Error = decodeStreamError(ev.Data)
+			return out, nil
+		default:
+			continue // an event this client does not know yet
+		}
+		if err := json.Unmarshal(ev.Data, target); err != nil {
+			return nil, fmt.Errorf("openai: decoding %s event: %w", ev.Event, err)
+		}
+		return out, nil
+	}
+}
+
+// Close closes the underlying connection.
+func (s *AssistantStream) Close() error {
+	return s.body.Close()
+}
+
+// Dispatch reads the stream until it is done, passing every event to h, and
+// returns the last state of the run it saw.
+func (s *AssistantStream) Dispatch(h AssistantEventHandler) (*Run, error) {
+	var last *Run
+	for {
+		ev, err := s.Next()
+		if err == io.EOF {
+			return last, nil
+		}
+		if err != nil {
+			return last, err
+		}
+		switch {
+		case ev.Thread != nil:
+			err = h.OnThreadCreated(ev.Thread)
+		case ev.Run != nil:
+			last = ev.Run
+			err = h.OnRun(ev.Event, ev.Run)
+		case ev.RunStep != nil:
+			err = h.OnRunStep(ev.Event, ev.RunStep)
+		case ev.RunStepDelta != nil:
+			err = h.OnRunStepDelta(ev.RunStepDelta)
+		case ev.Message != nil:
+			err = h.OnMessage(ev.Event, ev.Message)
+		case ev.MessageDelta != nil:
+			err = h.OnMessageDelta(ev.MessageDelta)
+		case ev.Error != nil:
+			if err = h.OnError(ev.Error); err == nil {
+				err = ev.Error
+			}
+		}
+		if err != nil {
+			return last, err
+		}
+	}
+}
+
+// decodeStreamError reads the data of an error event, which is either an
+// error object or one wrapped in {"error": ...}.
+func decodeStreamError(data []byte) *APIError {
+	var wrapped struct {
+		Error *APIError `json:"error"`
+	}
+	if json.Unmarshal(data, &wrapped) == nil && wrapped.Error != nil {
+		return wrapped.Error
+	}
+	apiErr := new(APIError)
+	if json.Unmarshal(data, apiErr) != nil || apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(data))
+	}
+	return apiErr
+}
+
+// openStream sends a streaming request answered with run events.
+func (r *RunsAPI) openStream(ctx context.Context, u string, body interface{}) (*AssistantStream, error) {
+	req, err := r.openAIClient.NewRequest(http.MethodPost, u, body, assistantsBeta)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.openAIClient.doStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &AssistantStream{body: resp.Body, dec: newSSEDecoder(resp.Body)}, nil
+}
+
+// CreateRunStream starts a run of an assistant on a thread and streams its events.
+func (r *RunsAPI) CreateRunStream(ctx context.Context, threadID string, rReq *RunRequest) (*AssistantStream, error) {
+	if err := r.openAIClient.checkUsage(ctx, EndpointAssistants, rReq.Model, ""); err != nil {
+		return nil, err
+	}
+	req := *rReq
+	req.Stream = true
+	return r.openStream(ctx, fmt.Sprintf("v1/threads/%s/runs", threadID), &req)
+}
+
+// CreateThreadAndRunStream creates a thread, starts a run on it and streams its events.
+func (r *RunsAPI) CreateThreadAndRunStream(ctx context.Context, trReq *ThreadRunRequest) (*AssistantStream, error) {
+	if err := r.openAIClient.checkUsage(ctx, EndpointAssistants, trReq.Model, ""); err != nil {
+		return nil, err
+	}
+	req := *trReq
+	req.Stream = true
+	return r.openStream(ctx, "v1/threads/runs", &req)
+}
+
+// SubmitToolOutputsStream answers the function calls of a run and streams
+// the events of the run as it continues.
+func (r *RunsAPI) SubmitToolOutputsStream(ctx context.Context, threadID, id string, outputs []ToolOutput) (*AssistantStream, error) {
+	u := fmt.Sprintf("v1/threads/%s/runs/%s/submit_tool_outputs", threadID, id)
+	body := struct {
+		ToolOutputs []ToolOutput `json:"tool_outputs"`
+		Stream      bool         `json:"stream"`
+	}{outputs, true}
+	return r.openStream(ctx, u, body)
+}
+
+// StreamRun starts a run and dispatches its events to h until the run stops.
+// When handleToolCall is set, the function calls of a run that requires
+// action are answered with it and the run's events keep flowing to h;
+// otherwise the run is returned in the requires_action status. A failed or
+// expired run is returned together with a *RunError.
+func (r *RunsAPI) StreamRun(ctx context.Context, threadID string, rReq *RunRequest, h AssistantEventHandler, handleToolCall ToolCallHandler) (*Run, error) {
+	stream, err := r.CreateRunStream(ctx, threadID, rReq)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		run, err := stream.Dispatch(h)
+		stream.Close()
+		if err != nil {
+			return run, err
+		}
+		if run == nil {
+			return nil, fmt.Errorf("openai: run stream ended without a run event")
+		}
+		if run.Status != RunStatusRequiresAction {
+			r.recordRunUsage(ctx, run)
+			return run, runFailure(run)
+		}
+		if handleToolCall == nil {
+			return run, nil
+		}
+
+		outputs, err := toolOutputs(ctx, run, handleToolCall)
+		if err != nil {
+			return run, err
+		}
+		stream, err = r.SubmitToolOutputsStream(ctx, run.ThreadID, run.ID, outputs)
+		if err != nil {
+			return run, err
+		}
+	}
+}
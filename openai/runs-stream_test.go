@@ -0,0 +1,63 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+const requiresActionRun = `{"id":"run_1","object":"thread.run","thread_id":"thread_1","status":"requires_action",` +
+	`"required_action":{"type":"submit_tool_outputs","submit_tool_outputs":{"tool_calls":[` +
+	`{"id":"call_1","type":"function","function":{"name":"weather","arguments":"{}"}}]}}}`
+
+func runStreamServer(t *testing.T, submitStatus int) *OpenAIClient {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/threads/thread_1/runs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", EventRunRequiresAction, requiresActionRun)
+		fmt.Fprint(w, "event: done\ndata: [DONE]\n\n")
+	})
+	mux.HandleFunc("POST /v1/threads/thread_1/runs/run_1/submit_tool_outputs", func(w http.ResponseWriter, r *http.Request) {
+		if submitStatus != http.StatusOK {
+			writeAPIError(w, submitStatus, "submit failed")
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", EventRunCompleted,
+			`{"id":"run_1","object":"thread.run","thread_id":"thread_1","status":"completed"}`)
+		fmt.Fprint(w, "event: done\ndata: [DONE]\n\n")
+	})
+	return newTestClient(t, mux)
+}
+
+func weatherTool(ctx context.Context, call ToolCall) (string, error) {
+	return "sunny", nil
+}
+
+func TestStreamRunSubmitsToolOutputs(t *testing.T) {
+	c := runStreamServer(t, http.StatusOK)
+	run, err := c.Runs.StreamRun(context.Background(), "thread_1", &RunRequest{AssistantID: "asst_1"}, NopAssistantEventHandler{}, weatherTool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Status != RunStatusCompleted {
+		t.Errorf("run status %q, want %q", run.Status, RunStatusCompleted)
+	}
+}
+
+func TestStreamRunFailedSubmit(t *testing.T) {
+	c := runStreamServer(t, http.StatusInternalServerError)
+	run, err := c.Runs.StreamRun(context.Background(), "thread_1", &RunRequest{AssistantID: "asst_1"}, NopAssistantEventHandler{}, weatherTool)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode() != http.StatusInternalServerError {
+		t.Fatalf("err = %v, want the submit's 500", err)
+	}
+	if run == nil || run.Status != RunStatusRequiresAction {
+		t.Errorf("run = %+v, want the run that required action", run)
+	}
+}
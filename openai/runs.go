@@ -0,0 +1,422 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+type RunsAPI Api
+
+// Statuses of a run.
+const (
+	RunStatusQueued         = "queued"
+	RunStatusInProgress     = "in_progress"
+	RunStatusRequiresAction = "requires_action"
+	RunStatusCancelling     = "cancelling"
+	RunStatusCancelled      = "cancelled"
+	RunStatusFailed         = "failed"
+	RunStatusCompleted      = "completed"
+	RunStatusIncomplete     = "incomplete"
+	RunStatusExpired        = "expired"
+)
+
+// Modes of ToolChoice.
+const (
+	ToolChoiceNone     = "none"
+	ToolChoiceAuto     = "auto"
+	ToolChoiceRequired = "required"
+)
+
+// ToolChoice controls which tool the model calls. It is sent as a string for
+// ToolChoiceNone, ToolChoiceAuto and ToolChoiceRequired, and as an object
+// forcing a particular tool otherwise.
+type ToolChoice struct {
+	Type     string `json:"type"`
+	Function *struct {
+		Name string `json:"name"`
+	} `json:"function,omitempty"`
+}
+
+// ToolChoiceFunction forces the model to call the function name.
+func ToolChoiceFunction(name string) *ToolChoice {
+	tc := &ToolChoice{Type: ToolTypeFunction}
+	tc.Function = &struct {
+		Name string `json:"name"`
+	}{name}
+	return tc
+}
+
+func (tc ToolChoice) MarshalJSON() ([]byte, error) {
+	switch tc.Type {
+	case ToolChoiceNone, ToolChoiceAuto, ToolChoiceRequired:
+		return json.Marshal(tc.Type)
+	}
+	type plain ToolChoice
+	return json.Marshal(plain(tc))
+}
+
+func (tc *ToolChoice) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*tc = ToolChoice{Type: s}
+		return nil
+	}
+	type plain ToolChoice
+	return json.Unmarshal(data, (*plain)(tc))
+}
+
+// RunTruncationStrategy controls how much of the thread a run sees.
+type RunTruncationStrategy struct {
+	// Type is "auto" or "last_messages".
+	Type         string `json:"type"`
+	LastMessages int    `json:"last_messages,omitempty"`
+}
+
+// RunRequest creates a run of an assistant on a thread. Fields left empty
+// take the assistant's values.
+type RunRequest struct {
+	AssistantID            string                 `json:"assistant_id"`
+	Model                  string                 `json:"model,omitempty"`
+	Instructions           string                 `json:"instructions,omitempty"`
+	AdditionalInstructions string                 `json:"additional_instructions,omitempty"`
+	AdditionalMessages     []MessageRequest       `json:"additional_messages,omitempty"`
+	Tools                  []AssistantTool        `json:"tools,omitempty"`
+	Metadata               map[string]string      `json:"metadata,omitempty"`
+	Temperature            *float64               `json:"temperature,omitempty"`
+	TopP                   *float64               `json:"top_p,omitempty"`
+	MaxPromptTokens        int                    `json:"max_prompt_tokens,omitempty"`
+	MaxCompletionTokens    int                    `json:"max_completion_tokens,omitempty"`
+	TruncationStrategy     *RunTruncationStrategy `json:"truncation_strategy,omitempty"`
+	ToolChoice             *ToolChoice            `json:"tool_choice,omitempty"`
+	ParallelToolCalls      *bool                  `json:"parallel_tool_calls,omitempty"`
+	ResponseFormat         *ResponseFormat        `json:"response_format,omitempty"`
+
+	// Stream is set by CreateRunStream; CreateRun ignores it.
+	Stream bool `json:"stream,omitempty"`
+}
+
+// ThreadRunRequest creates a thread and runs it in one request.
+type ThreadRunRequest struct {
+	AssistantID         string                 `json:"assistant_id"`
+	Thread              *ThreadRequest         `json:"thread,omitempty"`
+	Model               string                 `json:"model,omitempty"`
+	Instructions        string                 `json:"instructions,omitempty"`
+	Tools               []AssistantTool        `json:"tools,omitempty"`
+	ToolResources       *ToolResources         `json:"tool_resources,omitempty"`
+	Metadata            map[string]string      `json:"metadata,omitempty"`
+	Temperature         *float64               `json:"temperature,omitempty"`
+	TopP                *float64               `json:"top_p,omitempty"`
+	MaxPromptTokens     int                    `json:"max_prompt_tokens,omitempty"`
+	MaxCompletionTokens int                    `json:"max_completion_tokens,omitempty"`
+	TruncationStrategy  *RunTruncationStrategy `json:"truncation_strategy,omitempty"`
+	ToolChoice          *ToolChoice            `json:"tool_choice,omitempty"`
+	ParallelToolCalls   *bool                  `json:"parallel_tool_calls,omitempty"`
+	ResponseFormat      *ResponseFormat        `json:"response_format,omitempty"`
+
+	// Stream is set by CreateThreadAndRunStream; CreateThreadAndRun ignores it.
+	Stream bool `json:"stream,omitempty"`
+}
+
+type Run struct {
+	ID                  string                 `json:"id"`
+	Object              string                 `json:"object"`
+	CreatedAt           int64                  `json:"created_at"`
+	ThreadID            string                 `json:"thread_id"`
+	AssistantID         string                 `json:"assistant_id"`
+	Status              string                 `json:"status"`
+	RequiredAction      *RequiredAction        `json:"required_action"`
+	LastError           *RunError              `json:"last_error"`
+	ExpiresAt           int64                  `json:"expires_at"`
+	StartedAt           int64                  `json:"started_at"`
+	CancelledAt         int64                  `json:"cancelled_at"`
+	FailedAt            int64                  `json:"failed_at"`
+	CompletedAt         int64                  `json:"completed_at"`
+	IncompleteDetails   *IncompleteDetails     `json:"incomplete_details"`
+	Model               string                 `json:"model"`
+	Instructions        string                 `json:"instructions"`
+	Tools               []AssistantTool        `json:"tools"`
+	Metadata            map[string]string      `json:"metadata"`
+	Usage               *Usage                 `json:"usage"`
+	Temperature         *float64               `json:"temperature"`
+	TopP                *float64               `json:"top_p"`
+	MaxPromptTokens     int                    `json:"max_prompt_tokens"`
+	MaxCompletionTokens int                    `json:"max_completion_tokens"`
+	TruncationStrategy  *RunTruncationStrategy `json:"truncation_strategy"`
+	ToolChoice          *ToolChoice            `json:"tool_choice"`
+	ParallelToolCalls   bool                   `json:"parallel_tool_calls"`
+	ResponseFormat      *ResponseFormat        `json:"response_format"`
+}
+
+// RequiredAction lists the tool calls whose outputs a run is waiting for.
+type RequiredAction struct {
+	Type              string `json:"type"`
+	SubmitToolOutputs struct {
+		ToolCalls []ToolCall `json:"tool_calls"`
+	} `json:"submit_tool_outputs"`
+}
+
+// RunError is why a run failed. It is returned by PollRun for failed and
+// expired runs.
+type RunError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RunError) Error() string {
+	return fmt.Sprintf("openai: run %s: %s", e.Code, e.Message)
+}
+
+// ToolCall is a call of a tool by a run. Function calls are answered with
+// SubmitToolOutputs; the other tools run on the API side and report their
+// results in run steps.
+type ToolCall struct {
+	ID              string               `json:"id"`
+	Type            string               `json:"type"`
+	Function        *FunctionCall        `json:"function,omitempty"`
+	CodeInterpreter *CodeInterpreterCall `json:"code_interpreter,omitempty"`
+	FileSearch      json.RawMessage      `json:"file_search,omitempty"`
+}
+
+// FunctionCall holds the JSON encoded arguments of a function call and, in
+// run steps, the output submitted for it.
+type FunctionCall struct {
+	Name      string  `json:"name"`
+	Arguments string  `json:"arguments"`
+	Output    *string `json:"output,omitempty"`
+}
+
+type CodeInterpreterCall struct {
+	Input   string                  `json:"input"`
+	Outputs []CodeInterpreterOutput `json:"outputs"`
+}
+
+// CodeInterpreterOutput is either "logs" or an "image" written to a file.
+type CodeInterpreterOutput struct {
+	Type  string `json:"type"`
+	Logs  string `json:"logs,omitempty"`
+	Image *struct {
+		FileID string `json:"file_id"`
+	} `json:"image,omitempty"`
+}
+
+// ToolOutput answers the function call ToolCallID.
+type ToolOutput struct {
+	ToolCallID string `json:"tool_call_id"`
+	Output     string `json:"output"`
+}
+
+type RunList struct {
+	Object  string `json:"object"`
+	Data    []Run  `json:"data"`
+	FirstID string `json:"first_id"`
+	LastID  string `json:"last_id"`
+	HasMore bool   `json:"has_more"`
+}
+
+// Types of RunStep.
+const (
+	RunStepMessageCreation = "message_creation"
+	RunStepToolCalls       = "tool_calls"
+)
+
+// RunStep is a step a run took: creating a message or calling tools.
+type RunStep struct {
+	ID          string            `json:"id"`
+	Object      string            `json:"object"`
+	CreatedAt   int64             `json:"created_at"`
+	AssistantID string            `json:"assistant_id"`
+	ThreadID    string            `json:"thread_id"`
+	RunID       string            `json:"run_id"`
+	Type        string            `json:"type"`
+	Status      string            `json:"status"`
+	StepDetails RunStepDetails    `json:"step_details"`
+	LastError   *RunError         `json:"last_error"`
+	ExpiredAt   int64             `json:"expired_at"`
+	CancelledAt int64             `json:"cancelled_at"`
+	FailedAt    int64             `json:"failed_at"`
+	CompletedAt int64             `json:"completed_at"`
+	Metadata    map[string]string `json:"metadata"`
+	Usage       *Usage            `json:"usage"`
+}
+
+type RunStepDetails struct {
+	Type            string `json:"type"`
+	MessageCreation *struct {
+		MessageID string `json:"message_id"`
+	} `json:"message_creation,omitempty"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+}
+
+type RunStepList struct {
+	Object  string    `json:"object"`
+	Data    []RunStep `json:"data"`
+	FirstID string    `json:"first_id"`
+	LastID  string    `json:"last_id"`
+	HasMore bool      `json:"has_more"`
+}
+
+// CreateRun starts a run of an assistant on a thread.
+func (r *RunsAPI) CreateRun(ctx context.Context, threadID string, rReq *RunRequest) (*Run, *Response, error) {
+	if err := r.openAIClient.checkUsage(ctx, EndpointAssistants, rReq.Model, ""); err != nil {
+		return nil, nil, err
+	}
+
+	u := fmt.Sprintf("v1/threads/%s/runs", threadID)
+	if rReq.Stream {
+		req := *rReq
+		req.Stream = false
+		rReq = &req
+	}
+	return r.doRun(ctx, http.MethodPost, u, rReq)
+}
+
+// CreateThreadAndRun creates a thread and starts a run on it.
+func (r *RunsAPI) CreateThreadAndRun(ctx context.Context, trReq *ThreadRunRequest) (*Run, *Response, error) {
+	if err := r.openAIClient.checkUsage(ctx, EndpointAssistants, trReq.Model, ""); err != nil {
+		return nil, nil, err
+	}
+
+	if trReq.Stream {
+		req := *trReq
+		req.Stream = false
+		trReq = &req
+	}
+	return r.doRun(ctx, http.MethodPost, "v1/threads/runs", trReq)
+}
+
+// RetrieveRun returns a run.
+func (r *RunsAPI) RetrieveRun(ctx context.Context, threadID, id string) (*Run, *Response, error) {
+	u := fmt.Sprintf("v1/threads/%s/runs/%s", threadID, id)
+	return r.doRun(ctx, http.MethodGet, u, nil)
+}
+
+// ModifyRun replaces the metadata of a run.
+func (r *RunsAPI) ModifyRun(ctx context.Context, threadID, id string, metadata map[string]string) (*Run, *Response, error) {
+	u := fmt.Sprintf("v1/threads/%s/runs/%s", threadID, id)
+	body := struct {
+		Metadata map[string]string `json:"metadata"`
+	}{metadata}
+	return r.doRun(ctx, http.MethodPost, u, body)
+}
+
+// SubmitToolOutputs answers the function calls of a run in the
+// requires_action status. All calls must be answered at once.
+func (r *RunsAPI) SubmitToolOutputs(ctx context.Context, threadID, id string, outputs []ToolOutput) (*Run, *Response, error) {
+	u := fmt.Sprintf("v1/threads/%s/runs/%s/submit_tool_outputs", threadID, id)
+	body := struct {
+		ToolOutputs []ToolOutput `json:"tool_outputs"`
+	}{outputs}
+	return r.doRun(ctx, http.MethodPost, u, body)
+}
+
+// CancelRun cancels an in-progress run.
+func (r *RunsAPI) CancelRun(ctx context.Context, threadID, id string) (*Run, *Response, error) {
+	u := fmt.Sprintf("v1/threads/%s/runs/%s/cancel", threadID, id)
+	return r.doRun(ctx, http.MethodPost, u, nil)
+}
+
+// recordRunUsage passes the usage of a run that stopped to the client's
+// UsageRecorder. Runs only report their usage once they stop.
+func (r *RunsAPI) recordRunUsage(ctx context.Context, run *Run) {
+	if run.Usage != nil {
+		r.openAIClient.recordUsage(ctx, EndpointAssistants, run.Model, "", *run.Usage)
+	}
+}
+
+// doRun sends a request answered with a run.
+func (r *RunsAPI) doRun(ctx context.Context, method, u string, body interface{}) (*Run, *Response, error) {
+	req, err := r.openAIClient.NewRequest(method, u, body, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	run := new(Run)
+
+	resp, err := r.openAIClient.Do(ctx, req, run)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return run, resp, nil
+}
+
+// List returns the runs of a thread.
+func (r *RunsAPI) List(ctx context.Context, threadID string, opts *ListOptions) (*RunList, *Response, error) {
+	u := opts.encode(fmt.Sprintf("v1/threads/%s/runs", threadID))
+	req, err := r.openAIClient.NewRequest(http.MethodGet, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list := new(RunList)
+
+	resp, err := r.openAIClient.Do(ctx, req, list)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return list, resp, nil
+}
+
+// ListAll iterates over all runs of a thread, fetching pages as needed.
+func (r *RunsAPI) ListAll(ctx context.Context, threadID string, opts *ListOptions) iter.Seq2[Run, error] {
+	return paginate(ctx, opts, func(ctx context.Context, o *ListOptions) (page[Run], error) {
+		l, _, err := r.List(ctx, threadID, o)
+		if err != nil {
+			return page[Run]{}, err
+		}
+		return page[Run]{items: l.Data, hasMore: l.HasMore, lastID: l.LastID}, nil
+	}, func(run Run) string { return run.ID })
+}
+
+// RetrieveRunStep returns a step of a run.
+func (r *RunsAPI) RetrieveRunStep(ctx context.Context, threadID, runID, id string) (*RunStep, *Response, error) {
+	u := fmt.Sprintf("v1/threads/%s/runs/%s/steps/%s", threadID, runID, id)
+	req, err := r.openAIClient.NewRequest(http.MethodGet, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	step := new(RunStep)
+
+	resp, err := r.openAIClient.Do(ctx, req, step)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return step, resp, nil
+}
+
+// ListRunSteps returns the steps of a run.
+func (r *RunsAPI) ListRunSteps(ctx context.Context, threadID, runID string, opts *ListOptions) (*RunStepList, *Response, error) {
+	u := opts.encode(fmt.Sprintf("v1/threads/%s/runs/%s/steps", threadID, runID))
+	req, err := r.openAIClient.NewRequest(http.MethodGet, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list := new(RunStepList)
+
+	resp, err := r.openAIClient.Do(ctx, req, list)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return list, resp, nil
+}
+
+// ListAllRunSteps iterates over all steps of a run, fetching pages as needed.
+func (r *RunsAPI) ListAllRunSteps(ctx context.Context, threadID, runID string, opts *ListOptions) iter.Seq2[RunStep, error] {
+	return paginate(ctx, opts, func(ctx context.Context, o *ListOptions) (page[RunStep], error) {
+		l, _, err := r.ListRunSteps(ctx, threadID, runID, o)
+		if err != nil {
+			return page[RunStep]{}, err
+		}
+		return page[RunStep]{items: l.Data, hasMore: l.HasMore, lastID: l.LastID}, nil
+	}, func(step RunStep) string { return step.ID })
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// sseDone is the data of the event that ends an OpenAI stream.
+const sseDone = "[DONE]"
+
+// sseEvent is a single server-sent event.
+type sseEvent struct {
+	// Event is the name from the "event:" line, empty for unnamed events.
+	Event string
+	Data  []byte
+	ID    string
+}
+
+// sseDecoder reads server-sent events as described in
+// https://html.spec.whatwg.org/multipage/server-sent-events.html.
+type sseDecoder struct {
+	sc *bufio.Scanner
+}
+
+func newSSEDecoder(r io.Reader) *sseDecoder {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &sseDecoder{sc: sc}
+}
+
+// Next returns the next event, or io.EOF once the stream ends.
+func (d *sseDecoder) Next() (*sseEvent, error) {
+	var (
+		ev      sseEvent
+		data    bytes.Buffer
+		hasData bool
+	)
+	for d.sc.Scan() {
+		line := d.sc.Bytes()
+		if len(line) == 0 {
+			if hasData || ev.Event != "" {
+				ev.Data = data.Bytes()
+				return &ev, nil
+			}
+			continue
+		}
+		if line[0] == ':' {
+			continue // comment
+		}
+
+		field, value := line, []byte(nil)
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], line[i+1:]
+			value = bytes.TrimPrefix(value, []byte(" "))
+		}
+		switch string(field) {
+		case "event":
+			ev.Event = string(value)
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.Write(value)
+			hasData = true
+		case "id":
+			ev.ID = string(value)
+		}
+	}
+	if err := d.sc.Err(); err != nil {
+		return nil, err
+	}
+	// A final event without a trailing blank line is still delivered.
+	if hasData || ev.Event != "" {
+		ev.Data = data.Bytes()
+		return &ev, nil
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,365 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type ThreadsAPI Api
+
+type MessagesAPI Api
+
+// ThreadRequest creates or modifies a thread. Messages are only used on creation.
+type ThreadRequest struct {
+	Messages      []MessageRequest  `json:"messages,omitempty"`
+	ToolResources *ToolResources    `json:"tool_resources,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+}
+
+type Thread struct {
+	ID            string            `json:"id"`
+	Object        string            `json:"object"`
+	CreatedAt     int64             `json:"created_at"`
+	ToolResources *ToolResources    `json:"tool_resources"`
+	Metadata      map[string]string `json:"metadata"`
+}
+
+type ThreadDeleteResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
+
+// Types of MessageContent.
+const (
+	MessageContentText      = "text"
+	MessageContentImageFile = "image_file"
+	MessageContentImageURL  = "image_url"
+	MessageContentRefusal   = "refusal"
+)
+
+// MessageContent is one part of a thread message.
+type MessageContent struct {
+	Type      string            `json:"type"`
+	Text      *MessageText      `json:"text,omitempty"`
+	ImageFile *MessageImageFile `json:"image_file,omitempty"`
+	ImageURL  *MessageImageURL  `json:"image_url,omitempty"`
+	Refusal   string            `json:"refusal,omitempty"`
+}
+
+type MessageText struct {
+	Value       string              `json:"value"`
+	Annotations []MessageAnnotation `json:"annotations,omitempty"`
+}
+
+type MessageImageFile struct {
+	FileID string `json:"file_id"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type MessageImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// MessageAnnotation points from Text, between StartIndex and EndIndex, to a
+// file cited by file_search or written by code_interpreter.
+type MessageAnnotation struct {
+	Type         string `json:"type"`
+	Text         string `json:"text"`
+	StartIndex   int    `json:"start_index"`
+	EndIndex     int    `json:"end_index"`
+	FileCitation *struct {
+		FileID string `json:"file_id"`
+	} `json:"file_citation,omitempty"`
+	FilePath *struct {
+		FileID string `json:"file_id"`
+	} `json:"file_path,omitempty"`
+}
+
+// MessageAttachment adds a file to a message for the listed tools.
+type MessageAttachment struct {
+	FileID string          `json:"file_id"`
+	Tools  []AssistantTool `json:"tools,omitempty"`
+}
+
+// MessageRequest adds a message to a thread. Content is sent as plain text
+// unless ContentParts is set.
+type MessageRequest struct {
+	Role         string              `json:"role"`
+	Content      string              `json:"-"`
+	ContentParts []MessageContent    `json:"-"`
+	Attachments  []MessageAttachment `json:"attachments,omitempty"`
+	Metadata     map[string]string   `json:"metadata,omitempty"`
+}
+
+func (m MessageRequest) MarshalJSON() ([]byte, error) {
+	type plain MessageRequest
+	var content interface{} = m.Content
+	if m.ContentParts != nil {
+		content = m.ContentParts
+	}
+	return json.Marshal(struct {
+		plain
+		Content interface{} `json:"content"`
+	}{plain(m), content})
+}
+
+// ThreadMessage is a message in a thread.
+type ThreadMessage struct {
+	ID                string              `json:"id"`
+	Object            string              `json:"object"`
+	CreatedAt         int64               `json:"created_at"`
+	ThreadID          string              `json:"thread_id"`
+	Status            string              `json:"status"`
+	IncompleteDetails *IncompleteDetails  `json:"incomplete_details"`
+	CompletedAt       int64               `json:"completed_at"`
+	IncompleteAt      int64               `json:"incomplete_at"`
+	Role              string              `json:"role"`
+	Content           []MessageContent    `json:"content"`
+	AssistantID       string              `json:"assistant_id"`
+	RunID             string              `json:"run_id"`
+	Attachments       []MessageAttachment `json:"attachments"`
+	Metadata          map[string]string   `json:"metadata"`
+}
+
+// Text returns the text parts of the message, concatenated.
+func (m *ThreadMessage) Text() string {
+	var sb strings.Builder
+	for _, c := range m.Content {
+		if c.Type == MessageContentText && c.Text != nil {
+			sb.WriteString(c.Text.Value)
+		}
+	}
+	return sb.String()
+}
+
+type IncompleteDetails struct {
+	Reason string `json:"reason"`
+}
+
+type ThreadMessageList struct {
+	Object  string          `json:"object"`
+	Data    []ThreadMessage `json:"data"`
+	FirstID string          `json:"first_id"`
+	LastID  string          `json:"last_id"`
+	HasMore bool            `json:"has_more"`
+}
+
+type ThreadMessageDeleteResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
+
+// MessageListOptions page through a thread's messages, optionally only those
+// created by RunID.
+type MessageListOptions struct {
+	ListOptions
+	RunID string
+}
+
+func (o *MessageListOptions) encode(u string) string {
+	if o == nil {
+		return u
+	}
+	u = o.ListOptions.encode(u)
+	if o.RunID == "" {
+		return u
+	}
+	sep := "?"
+	if strings.Contains(u, "?") {
+		sep = "&"
+	}
+	return u + sep + "run_id=" + url.QueryEscape(o.RunID)
+}
+
+// CreateThread creates a thread, optionally with initial messages.
+func (t *ThreadsAPI) CreateThread(ctx context.Context, tReq *ThreadRequest) (*Thread, *Response, error) {
+	u := "v1/threads"
+	req, err := t.openAIClient.NewRequest(http.MethodPost, u, tReq, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	thread := new(Thread)
+
+	resp, err := t.openAIClient.Do(ctx, req, thread)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return thread, resp, nil
+}
+
+// RetrieveThread returns a thread.
+func (t *ThreadsAPI) RetrieveThread(ctx context.Context, id string) (*Thread, *Response, error) {
+	u := fmt.Sprintf("v1/threads/%s", id)
+	req, err := t.openAIClient.NewRequest(http.MethodGet, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	thread := new(Thread)
+
+	resp, err := t.openAIClient.Do(ctx, req, thread)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return thread, resp, nil
+}
+
+// ModifyThread changes the tool resources and metadata of a thread.
+func (t *ThreadsAPI) ModifyThread(ctx context.Context, id string, tReq *ThreadRequest) (*Thread, *Response, error) {
+	u := fmt.Sprintf("v1/threads/%s", id)
+	req, err := t.openAIClient.NewRequest(http.MethodPost, u, tReq, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	thread := new(Thread)
+
+	resp, err := t.openAIClient.Do(ctx, req, thread)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return thread, resp, nil
+}
+
+// DeleteThread deletes a thread.
+func (t *ThreadsAPI) DeleteThread(ctx context.Context, id string) (*ThreadDeleteResponse, *Response, error) {
+	u := fmt.Sprintf("v1/threads/%s", id)
+	req, err := t.openAIClient.NewRequest(http.MethodDelete, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tdResp := new(ThreadDeleteResponse)
+
+	resp, err := t.openAIClient.Do(ctx, req, tdResp)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return tdResp, resp, nil
+}
+
+// CreateMessage adds a message to a thread.
+func (m *MessagesAPI) CreateMessage(ctx context.Context, threadID string, mReq *MessageRequest) (*ThreadMessage, *Response, error) {
+	u := fmt.Sprintf("v1/threads/%s/messages", threadID)
+	req, err := m.openAIClient.NewRequest(http.MethodPost, u, mReq, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msg := new(ThreadMessage)
+
+	resp, err := m.openAIClient.Do(ctx, req, msg)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return msg, resp, nil
+}
+
+// RetrieveMessage returns a message of a thread.
+func (m *MessagesAPI) RetrieveMessage(ctx context.Context, threadID, id string) (*ThreadMessage, *Response, error) {
+	u := fmt.Sprintf("v1/threads/%s/messages/%s", threadID, id)
+	req, err := m.openAIClient.NewRequest(http.MethodGet, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msg := new(ThreadMessage)
+
+	resp, err := m.openAIClient.Do(ctx, req, msg)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return msg, resp, nil
+}
+
+// ModifyMessage replaces the metadata of a message.
+func (m *MessagesAPI) ModifyMessage(ctx context.Context, threadID, id string, metadata map[string]string) (*ThreadMessage, *Response, error) {
+	u := fmt.Sprintf("v1/threads/%s/messages/%s", threadID, id)
+	body := struct {
+		Metadata map[string]string `json:"metadata"`
+	}{metadata}
+	req, err := m.openAIClient.NewRequest(http.MethodPost, u, body, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msg := new(ThreadMessage)
+
+	resp, err := m.openAIClient.Do(ctx, req, msg)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return msg, resp, nil
+}
+
+// DeleteMessage deletes a message of a thread.
+func (m *MessagesAPI) DeleteMessage(ctx context.Context, threadID, id string) (*ThreadMessageDeleteResponse, *Response, error) {
+	u := fmt.Sprintf("v1/threads/%s/messages/%s", threadID, id)
+	req, err := m.openAIClient.NewRequest(http.MethodDelete, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mdResp := new(ThreadMessageDeleteResponse)
+
+	resp, err := m.openAIClient.Do(ctx, req, mdResp)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return mdResp, resp, nil
+}
+
+// List returns the messages of a thread, newest first unless opts says otherwise.
+func (m *MessagesAPI) List(ctx context.Context, threadID string, opts *MessageListOptions) (*ThreadMessageList, *Response, error) {
+	u := opts.encode(fmt.Sprintf("v1/threads/%s/messages", threadID))
+	req, err := m.openAIClient.NewRequest(http.MethodGet, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list := new(ThreadMessageList)
+
+	resp, err := m.openAIClient.Do(ctx, req, list)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return list, resp, nil
+}
+
+// ListAll iterates over all messages of a thread, fetching pages as needed.
+func (m *MessagesAPI) ListAll(ctx context.Context, threadID string, opts *MessageListOptions) iter.Seq2[ThreadMessage, error] {
+	var (
+		start ListOptions
+		runID string
+	)
+	if opts != nil {
+		start, runID = opts.ListOptions, opts.RunID
+	}
+	return paginate(ctx, &start, func(ctx context.Context, o *ListOptions) (page[ThreadMessage], error) {
+		l, _, err := m.List(ctx, threadID, &MessageListOptions{ListOptions: *o, RunID: runID})
+		if err != nil {
+			return page[ThreadMessage]{}, err
+		}
+		return page[ThreadMessage]{items: l.Data, hasMore: l.HasMore, lastID: l.LastID}, nil
+	}, func(msg ThreadMessage) string { return msg.ID })
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestMessageRequestContent(t *testing.T) {
+	tests := []struct {
+		name string
+		req  MessageRequest
+		want string
+	}{
+		{
+			name: "text",
+			req:  MessageRequest{Role: "user", Content: "Hello"},
+			want: `{"role":"user","content":"Hello"}`,
+		},
+		{
+			name: "parts",
+			req: MessageRequest{Role: "user", Content: "ignored", ContentParts: []MessageContent{
+				{Type: MessageContentText, Text: &MessageText{Value: "Look:"}},
+				{Type: MessageContentImageFile, ImageFile: &MessageImageFile{FileID: "file_1"}},
+			}},
+			want: `{"role":"user","content":[{"type":"text","text":{"value":"Look:"}},{"type":"image_file","image_file":{"file_id":"file_1"}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, data, tt.want)
+		}
+	}
+}
+
+func TestThreadMessageText(t *testing.T) {
+	m := &ThreadMessage{Content: []MessageContent{
+		{Type: MessageContentText, Text: &MessageText{Value: "It is "}},
+		{Type: MessageContentImageFile, ImageFile: &MessageImageFile{FileID: "file_1"}},
+		{Type: MessageContentText, Text: &MessageText{Value: "sunny."}},
+	}}
+	if got := m.Text(); got != "It is sunny." {
+		t.Errorf("Text() = %q", got)
+	}
+}
+
+func TestMessagesListAllKeepsRunID(t *testing.T) {
+	messages := []string{"msg_1", "msg_2", "msg_3"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/threads/thread_1/messages", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("run_id") != "run_1" {
+			writeAPIError(w, http.StatusBadRequest, "run_id = "+q.Get("run_id"))
+			return
+		}
+		// One message per page, after the cursor.
+		list := ThreadMessageList{Object: "list"}
+		for i, id := range messages {
+			if q.Get("after") == "" || i > 0 && messages[i-1] == q.Get("after") {
+				list.Data = []ThreadMessage{{ID: id, RunID: "run_1"}}
+				list.LastID, list.HasMore = id, i < len(messages)-1
+				break
+			}
+		}
+		json.NewEncoder(w).Encode(list)
+	})
+	c := newTestClient(t, mux)
+
+	var ids []string
+	for m, err := range c.Messages.ListAll(context.Background(), "thread_1", &MessageListOptions{RunID: "run_1"}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, m.ID)
+	}
+	if fmt.Sprint(ids) != fmt.Sprint(messages) {
+		t.Errorf("ListAll() = %v, want %v", ids, messages)
+	}
+}
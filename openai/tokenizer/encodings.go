@@ -0,0 +1,118 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package tokenizer
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+)
+
+// Names of the supported encodings.
+const (
+	R50kBase   = "r50k_base"
+	P50kBase   = "p50k_base"
+	CL100kBase = "cl100k_base"
+	O200kBase  = "o200k_base"
+)
+
+// Special token text shared by the encodings.
+const (
+	EndOfText   = "<|endoftext|>"
+	FimPrefix   = "<|fim_prefix|>"
+	FimMiddle   = "<|fim_middle|>"
+	FimSuffix   = "<|fim_suffix|>"
+	EndOfPrompt = "<|endofprompt|>"
+)
+
+// ErrEncodingUnavailable is returned when the rank table for a known encoding
+// is missing from the build.
+var ErrEncodingUnavailable = errors.New("tokenizer: encoding data not embedded")
+
+//go:embed data/*.tiktoken
+var data embed.FS
+
+var specialTokens = map[string]map[string]int{
+	R50kBase: {EndOfText: 50256},
+	P50kBase: {EndOfText: 50256},
+	CL100kBase: {
+		EndOfText:   100257,
+		FimPrefix:   100258,
+		FimMiddle:   100259,
+		FimSuffix:   100260,
+		EndOfPrompt: 100276,
+	},
+	O200kBase: {
+		EndOfText:   199999,
+		EndOfPrompt: 200018,
+	},
+}
+
+type lazyEncoding struct {
+	once sync.Once
+	enc  *Encoding
+	err  error
+}
+
+var (
+	encodingsMu sync.Mutex
+	encodings   = map[string]*lazyEncoding{}
+)
+
+// GetEncoding returns the named encoding, loading its embedded rank table on first use.
+func GetEncoding(name string) (*Encoding, error) {
+	if _, ok := specialTokens[name]; !ok {
+		return nil, fmt.Errorf("tokenizer: unknown encoding %q", name)
+	}
+
+	encodingsMu.Lock()
+	l, ok := encodings[name]
+	if !ok {
+		l = &lazyEncoding{}
+		encodings[name] = l
+	}
+	encodingsMu.Unlock()
+
+	l.once.Do(func() {
+		f, err := data.Open("data/" + name + ".tiktoken")
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				err = fmt.Errorf("%w: %s", ErrEncodingUnavailable, name)
+			}
+			l.err = err
+			return
+		}
+		defer f.Close()
+		l.enc, l.err = NewEncoding(name, f, name, specialTokens[name])
+	})
+	return l.enc, l.err
+}
+
+// Encode encodes text with the named encoding.
+func Encode(name, text string) ([]int, error) {
+	enc, err := GetEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+	return enc.Encode(text), nil
+}
+
+// Decode decodes tokens with the named encoding.
+func Decode(name string, tokens []int) (string, error) {
+	enc, err := GetEncoding(name)
+	if err != nil {
+		return "", err
+	}
+	return enc.Decode(tokens), nil
+}
+
+// Count returns the number of tokens in text under the named encoding.
+func Count(name, text string) (int, error) {
+	enc, err := GetEncoding(name)
+	if err != nil {
+		return 0, err
+	}
+	return enc.Count(text), nil
+}
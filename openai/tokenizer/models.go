@@ -0,0 +1,149 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package tokenizer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// modelEncodings maps exact model names to their encoding.
+var modelEncodings = map[string]string{
+	// chat
+	"o1":            O200kBase,
+	"o3":            O200kBase,
+	"o4-mini":       O200kBase,
+	"gpt-5":         O200kBase,
+	"gpt-4.1":       O200kBase,
+	"gpt-4o":        O200kBase,
+	"gpt-4":         CL100kBase,
+	"gpt-3.5-turbo": CL100kBase,
+	"gpt-3.5":       CL100kBase,
+	"gpt-35-turbo":  CL100kBase,
+
+	// base
+	"davinci-002": CL100kBase,
+	"babbage-002": CL100kBase,
+
+	// embeddings
+	"text-embedding-ada-002": CL100kBase,
+	"text-embedding-3-small": CL100kBase,
+	"text-embedding-3-large": CL100kBase,
+
+	// legacy completions
+	"text-davinci-003": P50kBase,
+	"text-davinci-002": P50kBase,
+	"text-davinci-001": R50kBase,
+	"text-curie-001":   R50kBase,
+	"text-babbage-001": R50kBase,
+	"text-ada-001":     R50kBase,
+	"davinci":          R50kBase,
+	"curie":            R50kBase,
+	"babbage":          R50kBase,
+	"ada":              R50kBase,
+
+	// code
+	"code-davinci-002": P50kBase,
+	"code-davinci-001": P50kBase,
+	"code-cushman-002": P50kBase,
+	"code-cushman-001": P50kBase,
+	"davinci-codex":    P50kBase,
+	"cushman-codex":    P50kBase,
+
+	// edits
+	"text-davinci-edit-001": P50kBase,
+	"code-davinci-edit-001": P50kBase,
+
+	// moderation
+	"text-moderation-latest": CL100kBase,
+	"text-moderation-stable": CL100kBase,
+	"omni-moderation-latest": O200kBase,
+
+	// legacy embeddings and search
+	"text-similarity-davinci-001":  R50kBase,
+	"text-similarity-curie-001":    R50kBase,
+	"text-similarity-babbage-001":  R50kBase,
+	"text-similarity-ada-001":      R50kBase,
+	"text-search-davinci-doc-001":  R50kBase,
+	"text-search-curie-doc-001":    R50kBase,
+	"text-search-babbage-doc-001":  R50kBase,
+	"text-search-ada-doc-001":      R50kBase,
+	"code-search-babbage-code-001": R50kBase,
+	"code-search-ada-code-001":     R50kBase,
+
+	"gpt2": R50kBase,
+}
+
+// modelPrefixEncodings maps model name prefixes, used for dated snapshots and
+// fine-tuned models, to their encoding. Longer prefixes are listed first.
+var modelPrefixEncodings = []struct {
+	prefix   string
+	encoding string
+}{
+	{"ft:gpt-4o", O200kBase},
+	{"ft:gpt-4.1", O200kBase},
+	{"ft:gpt-4", CL100kBase},
+	{"ft:gpt-3.5-turbo", CL100kBase},
+	{"ft:davinci-002", CL100kBase},
+	{"ft:babbage-002", CL100kBase},
+	{"chatgpt-4o-", O200kBase},
+	{"gpt-4o-", O200kBase},
+	{"gpt-4.1-", O200kBase},
+	{"gpt-4.5-", O200kBase},
+	{"gpt-5-", O200kBase},
+	{"gpt-oss-", O200kBase},
+	{"o1-", O200kBase},
+	{"o3-", O200kBase},
+	{"o4-", O200kBase},
+	{"gpt-4-", CL100kBase},
+	{"gpt-3.5-turbo-", CL100kBase},
+	{"gpt-35-turbo-", CL100kBase},
+}
+
+// EncodingNameForModel returns the name of the encoding used by model.
+func EncodingNameForModel(model string) (string, error) {
+	if name, ok := modelEncodings[model]; ok {
+		return name, nil
+	}
+	for _, p := range modelPrefixEncodings {
+		if strings.HasPrefix(model, p.prefix) {
+			return p.encoding, nil
+		}
+	}
+	return "", fmt.Errorf("tokenizer: no encoding known for model %q", model)
+}
+
+// EncodingForModel returns the encoding used by model.
+func EncodingForModel(model string) (*Encoding, error) {
+	name, err := EncodingNameForModel(model)
+	if err != nil {
+		return nil, err
+	}
+	return GetEncoding(name)
+}
+
+// Estimate approximates the number of tokens in text without a rank table.
+// It assumes three bytes per token, which overestimates typical English text,
+// so limits computed from it are respected.
+func Estimate(text string) int {
+	return (len(text) + 2) / 3
+}
+
+// CounterForModel returns a function that counts the tokens of a text for model.
+func CounterForModel(model string) (func(string) int, error) {
+	enc, err := EncodingForModel(model)
+	if err != nil {
+		return nil, err
+	}
+	return enc.Count, nil
+}
+
+// CounterForModelOrEstimate is like CounterForModel but falls back to
+// Estimate when no encoding is known for model, such as a custom deployment name.
+func CounterForModelOrEstimate(model string) func(string) int {
+	count, err := CounterForModel(model)
+	if err != nil {
+		return Estimate
+	}
+	return count
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package tokenizer
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ws matches the Unicode White_Space property, which is what \s means in the
+// reference patterns. RE2's \s only covers ASCII whitespace.
+const ws = `\t\n\v\f\r\x{85}\p{Z}`
+
+// The reference patterns end in `\s+(?!\S)|\s+`. RE2 has no lookahead, so both
+// alternatives are compiled as a single `\s+` and the lookahead is applied in split.
+var (
+	gpt2Pattern = `'s|'t|'re|'ve|'m|'ll|'d| ?\p{L}+| ?\p{N}+| ?[^` + ws + `\p{L}\p{N}]+|[` + ws + `]+`
+
+	cl100kPattern = `(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^` + ws + `\p{L}\p{N}]+[\r\n]*|[` + ws + `]*[\r\n]+|[` + ws + `]+`
+
+	o200kPattern = strings.Join([]string{
+		`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?`,
+		`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?`,
+		`\p{N}{1,3}`,
+		` ?[^` + ws + `\p{L}\p{N}]+[\r\n/]*`,
+		`[` + ws + `]*[\r\n]+`,
+		`[` + ws + `]+`,
+	}, "|")
+)
+
+type splitPattern struct {
+	re *regexp.Regexp
+	// newlineAware is set when the pattern has a dedicated newline alternative,
+	// so whitespace runs ending in a line break are never shortened.
+	newlineAware bool
+}
+
+var splitPatterns = map[string]splitPattern{
+	R50kBase:   {re: regexp.MustCompile(gpt2Pattern)},
+	P50kBase:   {re: regexp.MustCompile(gpt2Pattern)},
+	CL100kBase: {re: regexp.MustCompile(cl100kPattern), newlineAware: true},
+	O200kBase:  {re: regexp.MustCompile(o200kPattern), newlineAware: true},
+}
+
+// split breaks text into the pieces that are byte pair encoded independently.
+func (e *Encoding) split(text string) []string {
+	var pieces []string
+	for len(text) > 0 {
+		loc := e.pattern.FindStringIndex(text)
+		if loc == nil || loc[1] == 0 {
+			// Every rune is matched by one of the alternatives, so this only
+			// guards against looping forever on malformed input.
+			_, size := utf8.DecodeRuneInString(text)
+			pieces = append(pieces, text[:size])
+			text = text[size:]
+			continue
+		}
+		end := loc[1]
+		if n := e.lookaheadTrim(text, end); n > 0 {
+			end -= n
+		}
+		pieces = append(pieces, text[:end])
+		text = text[end:]
+	}
+	return pieces
+}
+
+// lookaheadTrim emulates `\s+(?!\S)`: a whitespace run of more than one rune
+// that is followed by a non-whitespace rune gives its last rune to the next piece.
+// It returns the number of bytes to give back.
+func (e *Encoding) lookaheadTrim(text string, end int) int {
+	match := text[:end]
+	if end == len(text) || !isSpace(match) {
+		return 0
+	}
+	if e.newlineAware {
+		if last := match[len(match)-1]; last == '\r' || last == '\n' {
+			return 0
+		}
+	}
+	if utf8.RuneCountInString(match) < 2 {
+		return 0
+	}
+	if next, _ := utf8.DecodeRuneInString(text[end:]); unicode.IsSpace(next) {
+		return 0
+	}
+	_, size := utf8.DecodeLastRuneInString(match)
+	return size
+}
+
+func isSpace(s string) bool {
+	for _, r := range s {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
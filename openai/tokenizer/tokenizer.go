@@ -0,0 +1,248 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+// Package tokenizer implements the byte pair encodings used by OpenAI models,
+// so that prompts can be measured locally before they are sent to the API.
+package tokenizer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Encoding is a byte pair encoding together with the pre-tokenization rules
+// and special tokens that belong to it.
+type Encoding struct {
+	Name string
+
+	pattern      *regexp.Regexp
+	newlineAware bool
+	ranks        map[string]int
+	decoder      map[int][]byte
+	special      map[string]int
+	specialDec   map[int]string
+	specialRe    *regexp.Regexp
+}
+
+// NewEncoding builds an Encoding from a tiktoken rank table read from r.
+// pattern names the pre-tokenization rules to use and must be one of the
+// encoding names defined by this package. special maps special token text to its id.
+func NewEncoding(name string, r io.Reader, pattern string, special map[string]int) (*Encoding, error) {
+	split, ok := splitPatterns[pattern]
+	if !ok {
+		return nil, fmt.Errorf("tokenizer: unknown split pattern for %q", pattern)
+	}
+
+	ranks, err := parseRanks(r)
+	if err != nil {
+		return nil, fmt.Errorf("tokenizer: %s: %w", name, err)
+	}
+
+	enc := &Encoding{
+		Name:         name,
+		pattern:      split.re,
+		newlineAware: split.newlineAware,
+		ranks:        ranks,
+		decoder:      make(map[int][]byte, len(ranks)),
+		special:      make(map[string]int, len(special)),
+		specialDec:   make(map[int]string, len(special)),
+	}
+	for tok, rank := range ranks {
+		enc.decoder[rank] = []byte(tok)
+	}
+
+	if len(special) > 0 {
+		quoted := make([]string, 0, len(special))
+		for tok, id := range special {
+			enc.special[tok] = id
+			enc.specialDec[id] = tok
+			quoted = append(quoted, regexp.QuoteMeta(tok))
+		}
+		// Longest first so that overlapping special tokens resolve to the longest match.
+		sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+		enc.specialRe = regexp.MustCompile(strings.Join(quoted, "|"))
+	}
+
+	return enc, nil
+}
+
+// parseRanks reads a tiktoken file: one "<base64 token> <rank>" pair per line.
+func parseRanks(r io.Reader) (map[string]int, error) {
+	ranks := make(map[string]int)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := bytes.TrimSpace(sc.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		fields := bytes.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected token and rank", line)
+		}
+		tok, err := base64.StdEncoding.DecodeString(string(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rank, err := strconv.Atoi(string(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ranks[string(tok)] = rank
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return nil, fmt.Errorf("empty rank table")
+	}
+	return ranks, nil
+}
+
+// Encode returns the tokens for text. Special token text such as "<|endoftext|>"
+// is treated as ordinary text.
+func (e *Encoding) Encode(text string) []int {
+	var tokens []int
+	for _, piece := range e.split(text) {
+		tokens = e.appendPiece(tokens, piece)
+	}
+	return tokens
+}
+
+// EncodeWithSpecial returns the tokens for text, mapping any special token
+// text to its reserved token id.
+func (e *Encoding) EncodeWithSpecial(text string) []int {
+	if e.specialRe == nil {
+		return e.Encode(text)
+	}
+
+	var tokens []int
+	for len(text) > 0 {
+		loc := e.specialRe.FindStringIndex(text)
+		if loc == nil {
+			for _, piece := range e.split(text) {
+				tokens = e.appendPiece(tokens, piece)
+			}
+			break
+		}
+		for _, piece := range e.split(text[:loc[0]]) {
+			tokens = e.appendPiece(tokens, piece)
+		}
+		tokens = append(tokens, e.special[text[loc[0]:loc[1]]])
+		text = text[loc[1]:]
+	}
+	return tokens
+}
+
+// Count returns the number of tokens Encode would produce for text.
+func (e *Encoding) Count(text string) int {
+	n := 0
+	for _, piece := range e.split(text) {
+		if _, ok := e.ranks[piece]; ok {
+			n++
+			continue
+		}
+		n += len(e.bytePairMerge([]byte(piece)))
+	}
+	return n
+}
+
+// Decode turns tokens back into text. Unknown token ids are skipped.
+func (e *Encoding) Decode(tokens []int) string {
+	return string(e.DecodeBytes(tokens))
+}
+
+// DecodeBytes turns tokens back into the raw bytes they represent.
+// A single token may hold a partial UTF-8 sequence.
+func (e *Encoding) DecodeBytes(tokens []int) []byte {
+	var buf []byte
+	for _, t := range tokens {
+		if b, ok := e.decoder[t]; ok {
+			buf = append(buf, b...)
+			continue
+		}
+		if s, ok := e.specialDec[t]; ok {
+			buf = append(buf, s...)
+		}
+	}
+	return buf
+}
+
+// SpecialTokens returns the special tokens of the encoding keyed by their text.
+func (e *Encoding) SpecialTokens() map[string]int {
+	out := make(map[string]int, len(e.special))
+	for k, v := range e.special {
+		out[k] = v
+	}
+	return out
+}
+
+func (e *Encoding) appendPiece(tokens []int, piece string) []int {
+	if rank, ok := e.ranks[piece]; ok {
+		return append(tokens, rank)
+	}
+	return append(tokens, e.bytePairMerge([]byte(piece))...)
+}
+
+// bytePairMerge applies the merge ranks to piece, starting from single bytes and
+// repeatedly joining the adjacent pair with the lowest rank.
+func (e *Encoding) bytePairMerge(piece []byte) []int {
+	if len(piece) == 1 {
+		return []int{e.ranks[string(piece)]}
+	}
+
+	// bounds[i] is the start offset of part i; the final entry is len(piece).
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+
+	rankOf := func(i int) int {
+		if i+2 >= len(bounds) {
+			return math.MaxInt
+		}
+		if r, ok := e.ranks[string(piece[bounds[i]:bounds[i+2]])]; ok {
+			return r
+		}
+		return math.MaxInt
+	}
+
+	pairRanks := make([]int, len(bounds)-1)
+	for i := range pairRanks {
+		pairRanks[i] = rankOf(i)
+	}
+
+	for len(bounds) > 2 {
+		minRank, minIdx := math.MaxInt, -1
+		for i := 0; i < len(pairRanks)-1; i++ {
+			if pairRanks[i] < minRank {
+				minRank, minIdx = pairRanks[i], i
+			}
+		}
+		if minIdx < 0 {
+			break
+		}
+
+		bounds = append(bounds[:minIdx+1], bounds[minIdx+2:]...)
+		pairRanks = append(pairRanks[:minIdx+1], pairRanks[minIdx+2:]...)
+		pairRanks[minIdx] = rankOf(minIdx)
+		if minIdx > 0 {
+			pairRanks[minIdx-1] = rankOf(minIdx - 1)
+		}
+	}
+
+	tokens := make([]int, 0, len(bounds)-1)
+	for i := 0; i < len(bounds)-1; i++ {
+		tokens = append(tokens, e.ranks[string(piece[bounds[i]:bounds[i+1]])])
+	}
+	return tokens
+}
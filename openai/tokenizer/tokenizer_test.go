@@ -0,0 +1,210 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package tokenizer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// goldenTokens are the token ids the reference tiktoken implementation
+// produces for each text, keyed by text and then by encoding.
+var goldenTokens = map[string]map[string][]int{
+	"\t\t tabs \u00a0nbsp\u3000ideographic space": {
+		R50kBase:   {197, 197, 22524, 220, 1849, 77, 24145, 5099, 222, 485, 6826, 2272},
+		P50kBase:   {197, 197, 22524, 220, 1849, 77, 24145, 5099, 222, 485, 6826, 2272},
+		CL100kBase: {298, 23204, 220, 4194, 5792, 23249, 95107, 3634},
+		O200kBase:  {335, 38191, 220, 5310, 9431, 1397, 617, 19045, 4918},
+	},
+	"  leading and trailing spaces  ": {
+		R50kBase:   {220, 3756, 290, 25462, 9029, 220, 220},
+		P50kBase:   {220, 3756, 290, 25462, 9029, 50257},
+		CL100kBase: {220, 6522, 323, 28848, 12908, 256},
+		O200kBase:  {220, 8117, 326, 57985, 18608, 256},
+	},
+	"<|endoftext|> is plain text here": {
+		R50kBase:   {27, 91, 437, 1659, 5239, 91, 29, 318, 8631, 2420, 994},
+		P50kBase:   {27, 91, 437, 1659, 5239, 91, 29, 318, 8631, 2420, 994},
+		CL100kBase: {27, 91, 8862, 728, 428, 91, 29, 374, 14733, 1495, 1618},
+		O200kBase:  {27, 91, 419, 1440, 919, 91, 29, 382, 21402, 2201, 2105},
+	},
+	"Hello, World! How's it going?": {
+		R50kBase:   {15496, 11, 2159, 0, 1374, 338, 340, 1016, 30},
+		P50kBase:   {15496, 11, 2159, 0, 1374, 338, 340, 1016, 30},
+		CL100kBase: {9906, 11, 4435, 0, 2650, 596, 433, 2133, 30},
+		O200kBase:  {13225, 11, 5922, 0, 3253, 885, 480, 2966, 30},
+	},
+	"I'M SURE THEY'LL say it's fine": {
+		R50kBase:   {40, 6, 44, 311, 11335, 33302, 6, 3069, 910, 340, 338, 3734},
+		P50kBase:   {40, 6, 44, 311, 11335, 33302, 6, 3069, 910, 340, 338, 3734},
+		CL100kBase: {40, 28703, 328, 4622, 63593, 6, 4178, 2019, 433, 596, 7060},
+		O200kBase:  {40, 95346, 336, 8141, 95381, 6, 7454, 2891, 4275, 8975},
+	},
+	"func main() {\n\tfmt.Println(\"hi\")\n}\n": {
+		R50kBase:   {20786, 1388, 3419, 1391, 198, 197, 69, 16762, 13, 18557, 18755, 7203, 5303, 4943, 198, 92, 198},
+		P50kBase:   {20786, 1388, 3419, 1391, 198, 197, 69, 16762, 13, 18557, 18755, 7203, 5303, 4943, 198, 92, 198},
+		CL100kBase: {2900, 1925, 368, 341, 11254, 12701, 446, 6151, 1158, 534},
+		O200kBase:  {5652, 2758, 416, 405, 24728, 28250, 568, 3686, 1896, 739},
+	},
+	"hello world": {
+		R50kBase:   {31373, 995},
+		P50kBase:   {31373, 995},
+		CL100kBase: {15339, 1917},
+		O200kBase:  {24912, 2375},
+	},
+	"https://example.com/path?q=1&r=two": {
+		R50kBase:   {5450, 1378, 20688, 13, 785, 14, 6978, 30, 80, 28, 16, 5, 81, 28, 11545},
+		P50kBase:   {5450, 1378, 20688, 13, 785, 14, 6978, 30, 80, 28, 16, 5, 81, 28, 11545},
+		CL100kBase: {2485, 1129, 8858, 916, 52076, 44882, 28, 16, 61717, 28, 20375},
+		O200kBase:  {4172, 1684, 18582, 1136, 119244, 93569, 28, 16, 160014, 28, 38397},
+	},
+	"line one\nline two\r\n\n\nindented:\n    code()\n": {
+		R50kBase:   {1370, 530, 198, 1370, 734, 201, 628, 198, 521, 4714, 25, 198, 220, 220, 220, 2438, 3419, 198},
+		P50kBase:   {1370, 530, 198, 1370, 734, 201, 628, 198, 521, 4714, 25, 198, 50258, 2438, 3419, 198},
+		CL100kBase: {1074, 832, 198, 1074, 1403, 201, 1432, 485, 16243, 512, 262, 2082, 746},
+		O200kBase:  {1137, 1001, 198, 1137, 1920, 201, 2499, 521, 23537, 734, 271, 3490, 1234},
+	},
+	"numbers 1234567 and 3.14159": {
+		R50kBase:   {77, 17024, 17031, 2231, 3134, 290, 513, 13, 1415, 19707},
+		P50kBase:   {77, 17024, 17031, 2231, 3134, 290, 513, 13, 1415, 19707},
+		CL100kBase: {38478, 220, 4513, 10961, 22, 323, 220, 18, 13, 9335, 2946},
+		O200kBase:  {85055, 220, 7633, 19354, 22, 326, 220, 18, 13, 16926, 4621},
+	},
+	"tiktoken is great!": {
+		R50kBase:   {83, 1134, 30001, 318, 1049, 0},
+		P50kBase:   {83, 1134, 30001, 318, 1049, 0},
+		CL100kBase: {83, 1609, 5963, 374, 2294, 0},
+		O200kBase:  {83, 8251, 2488, 382, 2212, 0},
+	},
+	"unicode: naïve café, 日本語のテキスト, emoji 🎉👍🏽": {
+		R50kBase:   {46903, 1098, 25, 41492, 40304, 11, 10545, 245, 98, 17312, 105, 45739, 252, 5641, 24336, 25084, 43302, 11, 44805, 12520, 236, 231, 41840, 235, 8582, 237, 121},
+		P50kBase:   {46903, 1098, 25, 41492, 40304, 11, 10545, 245, 98, 17312, 105, 45739, 252, 5641, 24336, 25084, 43302, 11, 44805, 12520, 236, 231, 41840, 235, 8582, 237, 121},
+		CL100kBase: {25535, 25, 95980, 588, 53050, 11, 76502, 22656, 45918, 252, 16144, 57933, 62903, 71634, 11, 43465, 11410, 236, 231, 9468, 239, 235, 9468, 237, 121},
+		O200kBase:  {107456, 25, 153475, 737, 30469, 11, 17428, 40909, 3385, 16056, 18368, 38236, 11, 74471, 139786, 231, 82514, 52622, 121},
+	},
+}
+
+func TestEncodeMatchesReference(t *testing.T) {
+	for text, byEncoding := range goldenTokens {
+		for name, want := range byEncoding {
+			enc, err := GetEncoding(name)
+			if err != nil {
+				t.Fatalf("GetEncoding(%q): %v", name, err)
+			}
+			if got := enc.Encode(text); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: Encode(%q) = %v, want %v", name, text, got, want)
+			}
+			if got := enc.Count(text); got != len(want) {
+				t.Errorf("%s: Count(%q) = %d, want %d", name, text, got, len(want))
+			}
+			if got := enc.Decode(want); got != text {
+				t.Errorf("%s: Decode(%v) = %q, want %q", name, want, got, text)
+			}
+		}
+	}
+}
+
+func TestEncodeWithSpecial(t *testing.T) {
+	tests := []struct {
+		encoding string
+		text     string
+		want     []int
+	}{
+		{R50kBase, "hello world<|endoftext|>", []int{31373, 995, 50256}},
+		{CL100kBase, "hello world<|endoftext|>", []int{15339, 1917, 100257}},
+		{O200kBase, "<|endoftext|>hello world", []int{199999, 24912, 2375}},
+	}
+	for _, tt := range tests {
+		enc, err := GetEncoding(tt.encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := enc.EncodeWithSpecial(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: EncodeWithSpecial(%q) = %v, want %v", tt.encoding, tt.text, got, tt.want)
+		}
+		if got := enc.Decode(tt.want); got != tt.text {
+			t.Errorf("%s: Decode(%v) = %q, want %q", tt.encoding, tt.want, got, tt.text)
+		}
+	}
+}
+
+// embeddedHashes are the SHA-256 sums tiktoken verifies the published rank
+// tables against.
+var embeddedHashes = map[string]string{
+	R50kBase:   "306cd27f03c1a714eca7108e03d66b7dc042abe8c258b44c199a7ed9838dd930",
+	P50kBase:   "94b5ca7dff4d00767bc256fdd1b27e5b17361d7b8a5f968547f9f23eb70d2069",
+	CL100kBase: "223921b76ee99bde995b7ff738513eef100fb51d18c93597a113bcffe865b2a7",
+	O200kBase:  "446a9538cb6c348e3516120d7c08b09f57c36495e2acfffe59a5bf8b0cfb1a2d",
+}
+
+func TestEmbeddedRankTables(t *testing.T) {
+	for name, want := range embeddedHashes {
+		f, err := data.Open("data/" + name + ".tiktoken")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != want {
+			t.Errorf("%s: sha256 = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestEncodingForModel(t *testing.T) {
+	tests := map[string]string{
+		"gpt-4o":                 O200kBase,
+		"gpt-4o-mini-2024-07-18": O200kBase,
+		"ft:gpt-4o-mini:org::id": O200kBase,
+		"gpt-4":                  CL100kBase,
+		"gpt-3.5-turbo-0125":     CL100kBase,
+		"text-embedding-3-small": CL100kBase,
+		"text-davinci-003":       P50kBase,
+		"davinci":                R50kBase,
+	}
+	for model, want := range tests {
+		enc, err := EncodingForModel(model)
+		if err != nil {
+			t.Errorf("EncodingForModel(%q): %v", model, err)
+			continue
+		}
+		if enc.Name != want {
+			t.Errorf("EncodingForModel(%q) = %s, want %s", model, enc.Name, want)
+		}
+	}
+}
+
+func TestCounterForModel(t *testing.T) {
+	const text = "tiktoken is great!"
+	count, err := CounterForModel("gpt-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := count(text); got != 6 {
+		t.Errorf("count = %d, want 6", got)
+	}
+
+	if _, err := CounterForModel("my-deployment"); err == nil {
+		t.Error("CounterForModel of an unknown model succeeded")
+	}
+	if got, want := CounterForModelOrEstimate("my-deployment")(text), Estimate(text); got != want {
+		t.Errorf("fallback count = %d, want the estimate %d", got, want)
+	}
+	if got := CounterForModelOrEstimate("gpt-4")(text); got != 6 {
+		t.Errorf("count = %d, want 6", got)
+	}
+}
+
+func TestGetEncodingUnknown(t *testing.T) {
+	if _, err := GetEncoding("nope"); err == nil || errors.Is(err, ErrEncodingUnavailable) {
+		t.Errorf("GetEncoding(nope) = %v, want an unknown encoding error", err)
+	}
+}
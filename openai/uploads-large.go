@@ -0,0 +1,277 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/AGMETEOR/openai-go/openai/internal/atomicfile"
+)
+
+// UploadLargeOptions configures UploadLarge.
+type UploadLargeOptions struct {
+	// Filename is the name of the file created. Required.
+	Filename string
+	// MimeType of the file. Defaults to one guessed from Filename's extension.
+	MimeType string
+	// PartSize is the size of every part but the last. Defaults to
+	// MaxUploadPartSize.
+	PartSize int64
+	// Concurrency is the number of parts uploaded at once, and so held in
+	// memory. Defaults to 4.
+	Concurrency int
+	// MaxRetries is the number of times a failed part is retried. Defaults to 3.
+	MaxRetries int
+	// ManifestPath, when set, is where the upload's progress is saved after
+	// every part. Calling UploadLarge again with the same manifest and input
+	// only sends the parts that are missing, as long as the upload has not
+	// expired. The manifest is removed once the upload completes.
+	ManifestPath string
+	// Progress, when set, is called after each part with the number of
+	// bytes uploaded so far.
+	Progress func(done, total int64)
+}
+
+// uploadManifest records the parts of an upload already sent.
+type uploadManifest struct {
+	UploadID  string `json:"upload_id"`
+	ExpiresAt int64  `json:"expires_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	Size      int64  `json:"size"`
+	PartSize  int64  `json:"part_size"`
+	// Parts holds, by index, the ID and MD5 of each part sent.
+	Parts []uploadManifestPart `json:"parts"`
+}
+
+type uploadManifestPart struct {
+	ID  string `json:"id,omitempty"`
+	MD5 string `json:"md5,omitempty"`
+}
+
+// UploadLarge uploads size bytes read from r as a file with purpose, splitting
+// them into parts sent in parallel through the uploads endpoints. The MD5 of
+// the whole input is verified when the upload is completed.
+func (up *UploadsAPI) UploadLarge(ctx context.Context, r io.Reader, size int64, purpose string, opts *UploadLargeOptions) (*Upload, error) {
+	if opts == nil || opts.Filename == "" {
+		return nil, errors.New("openai: UploadLarge needs a Filename")
+	}
+	if size <= 0 || size > MaxUploadSize {
+		return nil, fmt.Errorf("openai: upload size must be between 1 and %d bytes", int64(MaxUploadSize))
+	}
+	partSize := opts.PartSize
+	if partSize <= 0 || partSize > MaxUploadPartSize {
+		partSize = MaxUploadPartSize
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = 3
+	}
+	mimeType := opts.MimeType
+	if mimeType == "" {
+		mimeType = guessMimeType(opts.Filename)
+	}
+	numParts := int((size + partSize - 1) / partSize)
+
+	m := loadUploadManifest(opts.ManifestPath)
+	if m == nil || m.Filename != opts.Filename || m.Purpose != purpose || m.Size != size ||
+		m.PartSize != partSize || len(m.Parts) != numParts || time.Now().Add(time.Minute).Unix() > m.ExpiresAt {
+		upload, _, err := up.CreateUpload(ctx, &UploadRequest{Filename: opts.Filename, Purpose: purpose, Bytes: size, MimeType: mimeType})
+		if err != nil {
+			return nil, err
+		}
+		m = &uploadManifest{
+			UploadID:  upload.ID,
+			ExpiresAt: upload.ExpiresAt,
+			Filename:  opts.Filename,
+			Purpose:   purpose,
+			Size:      size,
+			PartSize:  partSize,
+			Parts:     make([]uploadManifestPart, numParts),
+		}
+		if m.ExpiresAt == 0 {
+			m.ExpiresAt = time.Now().Add(time.Hour).Unix()
+		}
+		if err := m.save(opts.ManifestPath); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		done     int64
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, concurrency)
+		whole    = md5.New()
+	)
+	progress := func(n int64) {
+		done += n
+		if opts.Progress != nil && firstErr == nil {
+			opts.Progress(done, size)
+		}
+	}
+
+	for i := 0; i < numParts; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		n := partSize
+		if rest := size - int64(i)*partSize; rest < n {
+			n = rest
+		}
+		data := make([]byte, n)
+		if _, err := io.ReadFull(r, data); err != nil {
+			<-sem
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("openai: reading upload part %d: %w", i, err)
+			}
+			mu.Unlock()
+			break
+		}
+		whole.Write(data)
+		sum := md5.Sum(data)
+		partMD5 := hex.EncodeToString(sum[:])
+
+		mu.Lock()
+		sent := m.Parts[i].ID != "" && m.Parts[i].MD5 == partMD5
+		if sent {
+			progress(n)
+		}
+		mu.Unlock()
+		if sent {
+			<-sem
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, data []byte) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			part, err := up.addPartWithRetry(ctx, m.UploadID, data, maxRetries)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("openai: upload part %d: %w", i, err)
+					cancel()
+				}
+				return
+			}
+			m.Parts[i] = uploadManifestPart{ID: part.ID, MD5: partMD5}
+			if err := m.save(opts.ManifestPath); err != nil && firstErr == nil {
+				firstErr = err
+				cancel()
+				return
+			}
+			progress(int64(len(data)))
+		}(i, data)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	partIDs := make([]string, numParts)
+	for i, p := range m.Parts {
+		partIDs[i] = p.ID
+	}
+	upload, _, err := up.CompleteUpload(ctx, m.UploadID, &CompleteUploadRequest{
+		PartIDs: partIDs,
+		MD5:     hex.EncodeToString(whole.Sum(nil)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts.ManifestPath != "" {
+		os.Remove(opts.ManifestPath)
+	}
+	return upload, nil
+}
+
+// addPartWithRetry adds data as a part of upload id, retrying temporary failures with backoff.
+func (up *UploadsAPI) addPartWithRetry(ctx context.Context, id string, data []byte, maxRetries int) (*UploadPart, error) {
+	for attempt := 0; ; attempt++ {
+		part, _, err := up.AddUploadPart(ctx, id, bytes.NewReader(data))
+		if err == nil {
+			return part, nil
+		}
+		if attempt >= maxRetries || !isRetryable(err) {
+			return nil, err
+		}
+		if err := sleepCtx(ctx, retryDelay(attempt, err)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// loadUploadManifest reads the manifest at path, or returns nil if there is none.
+func loadUploadManifest(path string) *uploadManifest {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	m := new(uploadManifest)
+	if json.Unmarshal(data, m) != nil {
+		return nil
+	}
+	return m
+}
+
+// save writes the manifest to path atomically. It does nothing when path is empty.
+func (m *uploadManifest) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, data)
+}
+
+// guessMimeType returns the MIME type of a file from its extension.
+func guessMimeType(filename string) string {
+	ext := filepath.Ext(filename)
+	if ext == ".jsonl" {
+		return "text/jsonl"
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,207 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// uploadsServer fakes the uploads endpoints. Parts whose data is in reject
+// are refused, and completing an upload checks the MD5 of its parts.
+type uploadsServer struct {
+	mu      sync.Mutex
+	uploads map[string]map[string][]byte // parts by ID, by upload ID
+	created int
+	sent    []string // data of every part accepted, in order
+	reject  map[string]bool
+	file    []byte // contents of the last file completed
+}
+
+func newUploadsServer(t *testing.T) (*uploadsServer, *OpenAIClient) {
+	s := &uploadsServer{uploads: make(map[string]map[string][]byte), reject: make(map[string]bool)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/uploads", func(w http.ResponseWriter, r *http.Request) {
+		var req UploadRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.created++
+		id := fmt.Sprintf("upload_%d", s.created)
+		s.uploads[id] = make(map[string][]byte)
+		json.NewEncoder(w).Encode(Upload{ID: id, Filename: req.Filename, Bytes: req.Bytes, Status: UploadStatusPending, ExpiresAt: 1 << 40})
+	})
+	mux.HandleFunc("POST /v1/uploads/{id}/parts", func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("data")
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		data, _ := io.ReadAll(f)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		parts, ok := s.uploads[r.PathValue("id")]
+		switch {
+		case !ok:
+			writeAPIError(w, http.StatusNotFound, "no such upload")
+			return
+		case s.reject[string(data)]:
+			writeAPIError(w, http.StatusBadRequest, "part rejected")
+			return
+		}
+		id := fmt.Sprintf("part_%d", len(s.sent)+1)
+		parts[id] = data
+		s.sent = append(s.sent, string(data))
+		json.NewEncoder(w).Encode(UploadPart{ID: id, UploadID: r.PathValue("id")})
+	})
+	mux.HandleFunc("POST /v1/uploads/{id}/complete", func(w http.ResponseWriter, r *http.Request) {
+		var req CompleteUploadRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var file []byte
+		for _, id := range req.PartIDs {
+			data, ok := s.uploads[r.PathValue("id")][id]
+			if !ok {
+				writeAPIError(w, http.StatusBadRequest, "unknown part "+id)
+				return
+			}
+			file = append(file, data...)
+		}
+		if sum := md5.Sum(file); req.MD5 != hex.EncodeToString(sum[:]) {
+			writeAPIError(w, http.StatusBadRequest, "md5 mismatch")
+			return
+		}
+		s.file = file
+		json.NewEncoder(w).Encode(Upload{ID: r.PathValue("id"), Status: UploadStatusCompleted, File: &File{ID: "file_1", Bytes: len(file)}})
+	})
+	return s, newTestClient(t, mux)
+}
+
+func uploadLarge(c *OpenAIClient, data string, opts UploadLargeOptions) (*Upload, error) {
+	opts.Filename, opts.PartSize = "train.jsonl", 4
+	return c.Uploads.UploadLarge(context.Background(), strings.NewReader(data), int64(len(data)), FilePurposeFineTune, &opts)
+}
+
+func TestUploadLarge(t *testing.T) {
+	s, c := newUploadsServer(t)
+	const data = "aaaabbbbccccdd"
+	var progress []int64
+	upload, err := uploadLarge(c, data, UploadLargeOptions{
+		Concurrency: 2,
+		Progress:    func(done, total int64) { progress = append(progress, done) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.Status != UploadStatusCompleted || string(s.file) != data {
+		t.Errorf("uploaded %q (%s), want %q", s.file, upload.Status, data)
+	}
+	if len(s.sent) != 4 {
+		t.Errorf("sent %d parts, want 4", len(s.sent))
+	}
+	if len(progress) != 4 || progress[3] != int64(len(data)) {
+		t.Errorf("progress = %v, want 4 calls ending at %d", progress, len(data))
+	}
+
+	if _, err := c.Uploads.UploadLarge(context.Background(), strings.NewReader("ab"), 3, FilePurposeFineTune, &UploadLargeOptions{Filename: "short.jsonl"}); err == nil {
+		t.Error("UploadLarge of a short input succeeded")
+	}
+}
+
+func TestUploadLargeResumes(t *testing.T) {
+	s, c := newUploadsServer(t)
+	manifest := filepath.Join(t.TempDir(), "upload.json")
+	const data = "aaaabbbbccccdd"
+
+	// The third part is rejected, after the first two were sent.
+	s.reject["cccc"] = true
+	_, err := uploadLarge(c, data, UploadLargeOptions{Concurrency: 1, ManifestPath: manifest})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("first UploadLarge() error = %v, want the rejected part", err)
+	}
+	if fmt.Sprint(s.sent) != "[aaaa bbbb]" {
+		t.Fatalf("sent %v before the failure", s.sent)
+	}
+	if _, err := os.Stat(manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+
+	delete(s.reject, "cccc")
+	var progress []int64
+	upload, err := uploadLarge(c, data, UploadLargeOptions{
+		Concurrency:  1,
+		ManifestPath: manifest,
+		Progress:     func(done, total int64) { progress = append(progress, done) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.created != 1 || upload.ID != "upload_1" {
+		t.Errorf("created %d uploads, want the first one resumed", s.created)
+	}
+	if fmt.Sprint(s.sent) != "[aaaa bbbb cccc dd]" || string(s.file) != data {
+		t.Errorf("sent %v, assembled %q; want only the missing parts sent", s.sent, s.file)
+	}
+	if fmt.Sprint(progress) != "[4 8 12 14]" {
+		t.Errorf("progress = %v, want the skipped parts counted", progress)
+	}
+	if _, err := os.Stat(manifest); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("manifest left behind: %v", err)
+	}
+}
+
+func TestUploadLargeResendsChangedParts(t *testing.T) {
+	s, c := newUploadsServer(t)
+	manifest := filepath.Join(t.TempDir(), "upload.json")
+
+	s.reject["dd"] = true
+	if _, err := uploadLarge(c, "aaaabbbbccccdd", UploadLargeOptions{Concurrency: 1, ManifestPath: manifest}); err == nil {
+		t.Fatal("first UploadLarge() succeeded")
+	}
+
+	// Same size, but the second part changed since: its MD5 no longer
+	// matches the manifest, so it is sent again.
+	const changed = "aaaaBBBBccccee"
+	if _, err := uploadLarge(c, changed, UploadLargeOptions{Concurrency: 1, ManifestPath: manifest}); err != nil {
+		t.Fatal(err)
+	}
+	if s.created != 1 {
+		t.Errorf("created %d uploads, want the first one resumed", s.created)
+	}
+	if fmt.Sprint(s.sent) != "[aaaa bbbb cccc BBBB ee]" || string(s.file) != changed {
+		t.Errorf("sent %v, assembled %q; want the changed parts sent again", s.sent, s.file)
+	}
+}
+
+func TestUploadLargeIgnoresOtherManifests(t *testing.T) {
+	s, c := newUploadsServer(t)
+	manifest := filepath.Join(t.TempDir(), "upload.json")
+
+	s.reject["cccc"] = true
+	if _, err := uploadLarge(c, "aaaabbbbcccc", UploadLargeOptions{Concurrency: 1, ManifestPath: manifest}); err == nil {
+		t.Fatal("first UploadLarge() succeeded")
+	}
+	delete(s.reject, "cccc")
+
+	// A different size makes the manifest useless: a new upload starts.
+	if _, err := uploadLarge(c, "aaaabbbbccccdd", UploadLargeOptions{Concurrency: 1, ManifestPath: manifest}); err != nil {
+		t.Fatal(err)
+	}
+	if s.created != 2 || !bytes.Equal(s.file, []byte("aaaabbbbccccdd")) {
+		t.Errorf("created %d uploads, assembled %q; want a new upload", s.created, s.file)
+	}
+}
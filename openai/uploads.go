@@ -0,0 +1,134 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type UploadsAPI Api
+
+// Limits of the uploads endpoints.
+const (
+	MaxUploadPartSize = 64 << 20
+	MaxUploadSize     = 8 << 30
+)
+
+// Statuses of an upload.
+const (
+	UploadStatusPending   = "pending"
+	UploadStatusCompleted = "completed"
+	UploadStatusCancelled = "cancelled"
+	UploadStatusExpired   = "expired"
+)
+
+type UploadRequest struct {
+	Filename string `json:"filename"`
+	Purpose  string `json:"purpose"`
+	Bytes    int64  `json:"bytes"`
+	MimeType string `json:"mime_type"`
+}
+
+type Upload struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	ExpiresAt int64  `json:"expires_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	Status    string `json:"status"`
+	// File is the file created once the upload is completed.
+	File *File `json:"file"`
+}
+
+type UploadPart struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	CreatedAt int64  `json:"created_at"`
+	UploadID  string `json:"upload_id"`
+}
+
+type CompleteUploadRequest struct {
+	// PartIDs lists the parts in the order they are assembled in.
+	PartIDs []string `json:"part_ids"`
+	// MD5 is the optional hex encoded checksum of the whole file, checked
+	// against the bytes uploaded.
+	MD5 string `json:"md5,omitempty"`
+}
+
+// CreateUpload creates an upload that parts can be added to. It expires an
+// hour after it is created.
+func (up *UploadsAPI) CreateUpload(ctx context.Context, upReq *UploadRequest) (*Upload, *Response, error) {
+	u := "v1/uploads"
+	req, err := up.openAIClient.NewRequest(http.MethodPost, u, upReq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	upload := new(Upload)
+
+	resp, err := up.openAIClient.Do(ctx, req, upload)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return upload, resp, nil
+}
+
+// AddUploadPart adds a part of at most MaxUploadPartSize bytes to an upload.
+func (up *UploadsAPI) AddUploadPart(ctx context.Context, id string, data io.Reader) (*UploadPart, *Response, error) {
+	u := fmt.Sprintf("v1/uploads/%s/parts", id)
+	req, err := up.openAIClient.newMultipartRequest(u, nil, "data", "part", data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	part := new(UploadPart)
+
+	resp, err := up.openAIClient.Do(ctx, req, part)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return part, resp, nil
+}
+
+// CompleteUpload assembles the parts into a file, which is returned in Upload.File.
+func (up *UploadsAPI) CompleteUpload(ctx context.Context, id string, cReq *CompleteUploadRequest) (*Upload, *Response, error) {
+	u := fmt.Sprintf("v1/uploads/%s/complete", id)
+	req, err := up.openAIClient.NewRequest(http.MethodPost, u, cReq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	upload := new(Upload)
+
+	resp, err := up.openAIClient.Do(ctx, req, upload)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return upload, resp, nil
+}
+
+// CancelUpload cancels an upload. No parts can be added to it afterwards.
+func (up *UploadsAPI) CancelUpload(ctx context.Context, id string) (*Upload, *Response, error) {
+	u := fmt.Sprintf("v1/uploads/%s/cancel", id)
+	req, err := up.openAIClient.NewRequest(http.MethodPost, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	upload := new(Upload)
+
+	resp, err := up.openAIClient.Do(ctx, req, upload)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return upload, resp, nil
+}
@@ -0,0 +1,339 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Usage reports the tokens consumed by a call. Endpoints that do not generate
+// text, such as embeddings, leave CompletionTokens at zero.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+
+	PromptTokensDetails     *PromptTokensDetails     `json:"prompt_tokens_details,omitempty"`
+	CompletionTokensDetails *CompletionTokensDetails `json:"completion_tokens_details,omitempty"`
+}
+
+type PromptTokensDetails struct {
+	CachedTokens int `json:"cached_tokens"`
+	AudioTokens  int `json:"audio_tokens"`
+}
+
+type CompletionTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
+	AudioTokens     int `json:"audio_tokens"`
+}
+
+// Add returns the sum of u and v. Token details are not carried over.
+func (u Usage) Add(v Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + v.PromptTokens,
+		CompletionTokens: u.CompletionTokens + v.CompletionTokens,
+		TotalTokens:      u.TotalTokens + v.TotalTokens,
+	}
+}
+
+// UsageRecord describes a single call that consumed tokens.
+// Before the call is sent, as passed to UsageLimiter, Usage and Cost are zero.
+type UsageRecord struct {
+	Time     time.Time
+	Endpoint string
+	Model    string
+	User     string
+	Tags     map[string]string
+	Usage    Usage
+	// Cost is in US dollars, computed from the client's ModelRegistry.
+	// It is zero for models without pricing.
+	Cost float64
+}
+
+// UsageRecorder receives a record of every call that reports token usage.
+// Implementations must be safe for concurrent use.
+type UsageRecorder interface {
+	RecordUsage(ctx context.Context, rec UsageRecord)
+}
+
+// UsageLimiter may be implemented by a UsageRecorder to refuse calls before
+// they are sent. A non-nil error from AllowUsage is returned to the caller.
+type UsageLimiter interface {
+	AllowUsage(ctx context.Context, rec UsageRecord) error
+}
+
+type usageUserKey struct{}
+type usageTagsKey struct{}
+
+// WithUsageUser attributes the calls made with ctx to user. It takes precedence
+// over the User field of the request.
+func WithUsageUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, usageUserKey{}, user)
+}
+
+// WithUsageTags attaches tags to the usage records of the calls made with ctx.
+// Tags already on ctx are kept unless overwritten.
+func WithUsageTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := make(map[string]string)
+	if prev, ok := ctx.Value(usageTagsKey{}).(map[string]string); ok {
+		for k, v := range prev {
+			merged[k] = v
+		}
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, usageTagsKey{}, merged)
+}
+
+func newUsageRecord(ctx context.Context, endpoint, model, user string) UsageRecord {
+	if u, ok := ctx.Value(usageUserKey{}).(string); ok {
+		user = u
+	}
+	tags, _ := ctx.Value(usageTagsKey{}).(map[string]string)
+	return UsageRecord{
+		Time:     time.Now(),
+		Endpoint: endpoint,
+		Model:    model,
+		User:     user,
+		Tags:     tags,
+	}
+}
+
+// checkUsage asks the client's UsageLimiter, if any, whether a call may be sent.
+func (c *OpenAIClient) checkUsage(ctx context.Context, endpoint, model, user string) error {
+	limiter, ok := c.UsageRecorder.(UsageLimiter)
+	if !ok {
+		return nil
+	}
+	return limiter.AllowUsage(ctx, newUsageRecord(ctx, endpoint, model, user))
+}
+
+// recordUsage passes the usage of a completed call to the client's UsageRecorder.
+func (c *OpenAIClient) recordUsage(ctx context.Context, endpoint, model, user string, usage Usage) {
+	if c.UsageRecorder == nil {
+		return
+	}
+	rec := newUsageRecord(ctx, endpoint, model, user)
+	rec.Usage = usage
+	if info, ok := c.modelRegistry().Lookup(model); ok {
+		rec.Cost = info.Cost(usage)
+	}
+	c.UsageRecorder.RecordUsage(ctx, rec)
+}
+
+func firstNonEmpty(s ...string) string {
+	for _, v := range s {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ErrBudgetExceeded is matched by the errors returned once a UsageTracker budget is spent.
+var ErrBudgetExceeded = errors.New("openai: usage budget exceeded")
+
+// BudgetExceededError reports which budget refused a call.
+type BudgetExceededError struct {
+	// Scope is "total", "user:<name>" or "tag:<key>=<value>".
+	Scope  string
+	Spent  UsageTotals
+	Budget UsageBudget
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("openai: usage budget exceeded for %s: spent $%.4f and %d tokens", e.Scope, e.Spent.Cost, e.Spent.TotalTokens)
+}
+
+func (e *BudgetExceededError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// UsageBudget limits spending. Zero fields are not limited.
+type UsageBudget struct {
+	MaxCost   float64
+	MaxTokens int
+}
+
+func (b UsageBudget) exceededBy(t UsageTotals) bool {
+	return (b.MaxCost > 0 && t.Cost >= b.MaxCost) || (b.MaxTokens > 0 && t.TotalTokens >= b.MaxTokens)
+}
+
+// UsageTotals aggregates usage records.
+type UsageTotals struct {
+	Calls            int
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Cost             float64
+}
+
+func (t *UsageTotals) add(rec UsageRecord) {
+	t.Calls++
+	t.PromptTokens += rec.Usage.PromptTokens
+	t.CompletionTokens += rec.Usage.CompletionTokens
+	t.TotalTokens += rec.Usage.TotalTokens
+	t.Cost += rec.Cost
+}
+
+// UsageTracker is an in-memory UsageRecorder that aggregates usage in total and
+// per model, user and tag, and refuses calls once a budget is spent.
+// Budgets are checked before a call is sent, so the call that crosses a
+// budget completes and the ones after it fail.
+type UsageTracker struct {
+	mu sync.Mutex
+
+	total   UsageTotals
+	byModel map[string]*UsageTotals
+	byUser  map[string]*UsageTotals
+	byTag   map[string]map[string]*UsageTotals
+
+	budget      UsageBudget
+	userBudgets map[string]UsageBudget
+	tagBudgets  map[string]map[string]UsageBudget
+}
+
+// NewUsageTracker returns an empty tracker with no budgets.
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{
+		byModel:     make(map[string]*UsageTotals),
+		byUser:      make(map[string]*UsageTotals),
+		byTag:       make(map[string]map[string]*UsageTotals),
+		userBudgets: make(map[string]UsageBudget),
+		tagBudgets:  make(map[string]map[string]UsageBudget),
+	}
+}
+
+// SetBudget limits the usage of all calls together.
+func (t *UsageTracker) SetBudget(b UsageBudget) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.budget = b
+}
+
+// SetUserBudget limits the usage of the calls attributed to user.
+func (t *UsageTracker) SetUserBudget(user string, b UsageBudget) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.userBudgets[user] = b
+}
+
+// SetTagBudget limits the usage of the calls tagged key=value.
+func (t *UsageTracker) SetTagBudget(key, value string, b UsageBudget) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tagBudgets[key] == nil {
+		t.tagBudgets[key] = make(map[string]UsageBudget)
+	}
+	t.tagBudgets[key][value] = b
+}
+
+// RecordUsage implements UsageRecorder.
+func (t *UsageTracker) RecordUsage(ctx context.Context, rec UsageRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.total.add(rec)
+	totalsFor(t.byModel, rec.Model).add(rec)
+	if rec.User != "" {
+		totalsFor(t.byUser, rec.User).add(rec)
+	}
+	for k, v := range rec.Tags {
+		if t.byTag[k] == nil {
+			t.byTag[k] = make(map[string]*UsageTotals)
+		}
+		totalsFor(t.byTag[k], v).add(rec)
+	}
+}
+
+// AllowUsage implements UsageLimiter.
+func (t *UsageTracker) AllowUsage(ctx context.Context, rec UsageRecord) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.budget.exceededBy(t.total) {
+		return &BudgetExceededError{Scope: "total", Spent: t.total, Budget: t.budget}
+	}
+	if b, ok := t.userBudgets[rec.User]; ok && rec.User != "" {
+		if spent := valueOf(t.byUser[rec.User]); b.exceededBy(spent) {
+			return &BudgetExceededError{Scope: "user:" + rec.User, Spent: spent, Budget: b}
+		}
+	}
+	for k, v := range rec.Tags {
+		b, ok := t.tagBudgets[k][v]
+		if !ok {
+			continue
+		}
+		if spent := valueOf(t.byTag[k][v]); b.exceededBy(spent) {
+			return &BudgetExceededError{Scope: "tag:" + k + "=" + v, Spent: spent, Budget: b}
+		}
+	}
+	return nil
+}
+
+// Total returns the usage of all recorded calls.
+func (t *UsageTracker) Total() UsageTotals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.total
+}
+
+// ByModel returns the usage broken down by model.
+func (t *UsageTracker) ByModel() map[string]UsageTotals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return snapshot(t.byModel)
+}
+
+// ByUser returns the usage broken down by user. Calls without a user are left out.
+func (t *UsageTracker) ByUser() map[string]UsageTotals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return snapshot(t.byUser)
+}
+
+// ByTag returns the usage broken down by the values of the tag key.
+func (t *UsageTracker) ByTag(key string) map[string]UsageTotals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return snapshot(t.byTag[key])
+}
+
+// Reset clears the recorded usage. Budgets are kept.
+func (t *UsageTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total = UsageTotals{}
+	t.byModel = make(map[string]*UsageTotals)
+	t.byUser = make(map[string]*UsageTotals)
+	t.byTag = make(map[string]map[string]*UsageTotals)
+}
+
+func totalsFor(m map[string]*UsageTotals, key string) *UsageTotals {
+	t, ok := m[key]
+	if !ok {
+		t = new(UsageTotals)
+		m[key] = t
+	}
+	return t
+}
+
+func valueOf(t *UsageTotals) UsageTotals {
+	if t == nil {
+		return UsageTotals{}
+	}
+	return *t
+}
+
+func snapshot(m map[string]*UsageTotals) map[string]UsageTotals {
+	out := make(map[string]UsageTotals, len(m))
+	for k, v := range m {
+		out[k] = *v
+	}
+	return out
+}
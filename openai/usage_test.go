@@ -0,0 +1,206 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func usageRecord(model, user string, tokens int, cost float64, tags map[string]string) UsageRecord {
+	return UsageRecord{
+		Model: model,
+		User:  user,
+		Tags:  tags,
+		Usage: Usage{PromptTokens: tokens - 1, CompletionTokens: 1, TotalTokens: tokens},
+		Cost:  cost,
+	}
+}
+
+func TestUsageTrackerAggregates(t *testing.T) {
+	tr := NewUsageTracker()
+	ctx := context.Background()
+	tr.RecordUsage(ctx, usageRecord("gpt-4o", "alice", 10, 0.5, map[string]string{"team": "search"}))
+	tr.RecordUsage(ctx, usageRecord("gpt-4o", "bob", 20, 1, map[string]string{"team": "search", "env": "prod"}))
+	tr.RecordUsage(ctx, usageRecord("gpt-4o-mini", "alice", 30, 0.25, map[string]string{"team": "ads"}))
+	tr.RecordUsage(ctx, usageRecord("gpt-4o-mini", "", 40, 0.25, nil))
+
+	if got := tr.Total(); got.Calls != 4 || got.TotalTokens != 100 || got.PromptTokens != 96 || got.CompletionTokens != 4 || got.Cost != 2 {
+		t.Errorf("Total() = %+v", got)
+	}
+	tests := []struct {
+		name   string
+		totals map[string]UsageTotals
+		want   map[string]int // tokens by key
+	}{
+		{"by model", tr.ByModel(), map[string]int{"gpt-4o": 30, "gpt-4o-mini": 70}},
+		{"by user", tr.ByUser(), map[string]int{"alice": 40, "bob": 20}},
+		{"by team", tr.ByTag("team"), map[string]int{"search": 30, "ads": 30}},
+		{"by env", tr.ByTag("env"), map[string]int{"prod": 20}},
+		{"by unknown tag", tr.ByTag("region"), map[string]int{}},
+	}
+	for _, tt := range tests {
+		got := make(map[string]int)
+		for k, v := range tt.totals {
+			got[k] = v.TotalTokens
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: tokens = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	tr.Reset()
+	if got := tr.Total(); got.Calls != 0 || len(tr.ByModel()) != 0 {
+		t.Errorf("after Reset: %+v, %v", got, tr.ByModel())
+	}
+}
+
+func TestUsageTrackerBudgets(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*UsageTracker)
+		spent     []UsageRecord
+		call      UsageRecord
+		wantScope string
+	}{
+		{
+			name:      "total cost spent",
+			configure: func(tr *UsageTracker) { tr.SetBudget(UsageBudget{MaxCost: 1}) },
+			spent:     []UsageRecord{usageRecord("gpt-4o", "", 10, 1, nil)},
+			wantScope: "total",
+		},
+		{
+			name:      "total tokens spent",
+			configure: func(tr *UsageTracker) { tr.SetBudget(UsageBudget{MaxTokens: 15}) },
+			spent:     []UsageRecord{usageRecord("gpt-4o", "", 10, 0, nil), usageRecord("gpt-4o", "", 10, 0, nil)},
+			wantScope: "total",
+		},
+		{
+			name:      "total under budget",
+			configure: func(tr *UsageTracker) { tr.SetBudget(UsageBudget{MaxCost: 1, MaxTokens: 100}) },
+			spent:     []UsageRecord{usageRecord("gpt-4o", "", 10, 0.5, nil)},
+		},
+		{
+			name:      "user spent",
+			configure: func(tr *UsageTracker) { tr.SetUserBudget("alice", UsageBudget{MaxTokens: 10}) },
+			spent:     []UsageRecord{usageRecord("gpt-4o", "alice", 10, 0, nil)},
+			call:      UsageRecord{User: "alice"},
+			wantScope: "user:alice",
+		},
+		{
+			name:      "other user unaffected",
+			configure: func(tr *UsageTracker) { tr.SetUserBudget("alice", UsageBudget{MaxTokens: 10}) },
+			spent:     []UsageRecord{usageRecord("gpt-4o", "alice", 10, 0, nil)},
+			call:      UsageRecord{User: "bob"},
+		},
+		{
+			name:      "tag spent",
+			configure: func(tr *UsageTracker) { tr.SetTagBudget("team", "search", UsageBudget{MaxCost: 0.5}) },
+			spent:     []UsageRecord{usageRecord("gpt-4o", "", 10, 0.5, map[string]string{"team": "search"})},
+			call:      UsageRecord{Tags: map[string]string{"team": "search"}},
+			wantScope: "tag:team=search",
+		},
+		{
+			name:      "other tag value unaffected",
+			configure: func(tr *UsageTracker) { tr.SetTagBudget("team", "search", UsageBudget{MaxCost: 0.5}) },
+			spent:     []UsageRecord{usageRecord("gpt-4o", "", 10, 0.5, map[string]string{"team": "search"})},
+			call:      UsageRecord{Tags: map[string]string{"team": "ads"}},
+		},
+		{
+			name:      "no budget",
+			configure: func(tr *UsageTracker) {},
+			spent:     []UsageRecord{usageRecord("gpt-4o", "alice", 1000, 100, nil)},
+			call:      UsageRecord{User: "alice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewUsageTracker()
+			tt.configure(tr)
+			for _, rec := range tt.spent {
+				tr.RecordUsage(context.Background(), rec)
+			}
+			err := tr.AllowUsage(context.Background(), tt.call)
+			if tt.wantScope == "" {
+				if err != nil {
+					t.Errorf("AllowUsage = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrBudgetExceeded) {
+				t.Fatalf("AllowUsage = %v, want ErrBudgetExceeded", err)
+			}
+			var budgetErr *BudgetExceededError
+			if !errors.As(err, &budgetErr) || budgetErr.Scope != tt.wantScope {
+				t.Errorf("AllowUsage = %v, want scope %q", err, tt.wantScope)
+			}
+		})
+	}
+}
+
+func TestUsageRecordedAcrossEndpoints(t *testing.T) {
+	var calls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, `{"model":"gpt-4o","usage":{"prompt_tokens":8,"completion_tokens":2,"total_tokens":10}}`)
+	})
+	mux.HandleFunc("POST /v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, `{"model":"text-embedding-3-small","data":[{"index":0,"embedding":[1]}],"usage":{"prompt_tokens":5,"total_tokens":5}}`)
+	})
+	mux.HandleFunc("POST /v1/threads/thread_1/runs", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, `{"id":"run_1","thread_id":"thread_1","status":"queued"}`)
+	})
+	mux.HandleFunc("GET /v1/threads/thread_1/runs/run_1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"run_1","thread_id":"thread_1","model":"gpt-4o-mini","status":"completed",
+			"usage":{"prompt_tokens":90,"completion_tokens":10,"total_tokens":100}}`)
+	})
+	c := newTestClient(t, mux)
+	tr := NewUsageTracker()
+	c.UsageRecorder = tr
+	ctx := WithUsageTags(context.Background(), map[string]string{"feature": "support"})
+
+	if _, _, err := c.Chat.CreateChatCompletion(ctx, &ChatRequest{Model: "gpt-4o", User: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Embeddings.CreateEmbeddings(ctx, &EmbeddingRequest{Model: "text-embedding-3-small", Input: EmbeddingText("hi")}); err != nil {
+		t.Fatal(err)
+	}
+	run, _, err := c.Runs.CreateRun(ctx, "thread_1", &RunRequest{AssistantID: "asst_1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Runs.PollRun(ctx, run.ThreadID, run.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	byModel := tr.ByModel()
+	if byModel["gpt-4o"].TotalTokens != 10 || byModel["text-embedding-3-small"].TotalTokens != 5 || byModel["gpt-4o-mini"].TotalTokens != 100 {
+		t.Errorf("ByModel() = %+v", byModel)
+	}
+	if got := tr.ByUser()["alice"].TotalTokens; got != 10 {
+		t.Errorf("alice used %d tokens, want 10", got)
+	}
+	if got := tr.ByTag("feature")["support"]; got.Calls != 3 || got.TotalTokens != 115 {
+		t.Errorf("feature=support = %+v, want 3 calls and 115 tokens", got)
+	}
+
+	// Once the budget is spent, calls are refused before they are sent.
+	tr.SetBudget(UsageBudget{MaxTokens: 100})
+	before := atomic.LoadInt32(&calls)
+	if _, _, err := c.Chat.CreateChatCompletion(ctx, &ChatRequest{Model: "gpt-4o"}); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("chat: err = %v, want ErrBudgetExceeded", err)
+	}
+	if _, _, err := c.Runs.CreateRun(ctx, "thread_1", &RunRequest{AssistantID: "asst_1"}); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("run: err = %v, want ErrBudgetExceeded", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != before {
+		t.Errorf("%d refused calls reached the server", n-before)
+	}
+}
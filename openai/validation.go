@@ -0,0 +1,40 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"strings"
+)
+
+// ValidationError describes a request field that the API would reject.
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return "openai: invalid " + e.Field + ": " + e.Reason
+}
+
+// ValidationErrors collects every problem found in a request.
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Field + ": " + e.Reason
+	}
+	return "openai: invalid request: " + strings.Join(msgs, "; ")
+}
+
+func (errs *ValidationErrors) add(field, reason string) {
+	*errs = append(*errs, &ValidationError{Field: field, Reason: reason})
+}
+
+// err returns errs as an error, or nil when it is empty.
+func (errs ValidationErrors) err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
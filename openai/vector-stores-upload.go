@@ -0,0 +1,180 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// VectorStoreFileBatchError is returned when some files of a batch could not
+// be indexed, or the batch was cancelled. List the files of the batch with
+// the VectorStoreFileStatusFailed filter to see which.
+type VectorStoreFileBatchError struct {
+	ID         string
+	Status     string
+	FileCounts VectorStoreFileCounts
+}
+
+func (e *VectorStoreFileBatchError) Error() string {
+	return fmt.Sprintf("openai: vector store file batch %s %s: %d of %d files failed, %d cancelled",
+		e.ID, e.Status, e.FileCounts.Failed, e.FileCounts.Total, e.FileCounts.Cancelled)
+}
+
+// UploadAndPollOptions configures UploadAndPoll. The zero value is usable.
+type UploadAndPollOptions struct {
+	// ChunkingStrategy splits the files. Defaults to the API's automatic strategy.
+	ChunkingStrategy *ChunkingStrategy
+	// Concurrency is the number of files uploaded at once. Defaults to 4.
+	Concurrency int
+	// PollInterval is the first delay between status checks of the batch,
+	// which doubles up to ten seconds. Defaults to 500ms.
+	PollInterval time.Duration
+}
+
+// UploadAndPoll uploads the files at paths with the assistants purpose,
+// attaches them to the vector store in one file batch and waits until they
+// are indexed. If anything fails, the files already uploaded are deleted, and
+// a batch that was created is cancelled first. A batch with failed or
+// cancelled files is not a failure: it is returned together with a
+// *VectorStoreFileBatchError and its files are kept.
+func (v *VectorStoresAPI) UploadAndPoll(ctx context.Context, vectorStoreID string, paths []string, opts *UploadAndPollOptions) (*VectorStoreFileBatch, error) {
+	if opts == nil {
+		opts = &UploadAndPollOptions{}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("openai: no files to upload to vector store %s", vectorStoreID)
+	}
+
+	fileIDs, err := v.uploadFiles(ctx, paths, opts.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	batch, _, err := v.CreateVectorStoreFileBatch(ctx, vectorStoreID, &VectorStoreFileBatchRequest{
+		FileIDs:          fileIDs,
+		ChunkingStrategy: opts.ChunkingStrategy,
+	})
+	if err != nil {
+		v.deleteFiles(ctx, "", fileIDs)
+		return nil, err
+	}
+
+	done, err := v.PollVectorStoreFileBatch(ctx, vectorStoreID, batch.ID, opts.PollInterval)
+	var batchErr *VectorStoreFileBatchError
+	if err != nil && !errors.As(err, &batchErr) {
+		v.CancelVectorStoreFileBatch(context.WithoutCancel(ctx), vectorStoreID, batch.ID)
+		v.deleteFiles(ctx, vectorStoreID, fileIDs)
+	}
+	return done, err
+}
+
+// deleteFiles removes uploaded files from the vector store, if it holds them,
+// and deletes them, even if ctx is done, so no orphaned files are left behind.
+func (v *VectorStoresAPI) deleteFiles(ctx context.Context, vectorStoreID string, ids []string) {
+	ctx = context.WithoutCancel(ctx)
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if vectorStoreID != "" {
+			v.DeleteVectorStoreFile(ctx, vectorStoreID, id)
+		}
+		v.openAIClient.File.DeleteFile(ctx, id)
+	}
+}
+
+// uploadFiles uploads paths concurrently and returns their file IDs in order.
+func (v *VectorStoresAPI) uploadFiles(ctx context.Context, paths []string, concurrency int) ([]string, error) {
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	ids := make([]string, len(paths))
+
+	uploadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, concurrency)
+	)
+
+	for i, path := range paths {
+		select {
+		case sem <- struct{}{}:
+		case <-uploadCtx.Done():
+		}
+		if uploadCtx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			file, _, err := v.openAIClient.File.UploadFile(uploadCtx, &FileUploadRequest{File: path, Purpose: FilePurposeAssistants})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("openai: uploading %s: %w", path, err)
+					cancel()
+				}
+				return
+			}
+			ids[i] = file.ID
+		}(i, path)
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		v.deleteFiles(ctx, "", ids)
+		return nil, firstErr
+	}
+	return ids, nil
+}
+
+// PollVectorStoreFileBatch waits while the files of a batch are being indexed
+// and returns the batch once it is done. interval is the first delay between
+// checks, 500ms if zero. A batch with failed or cancelled files is returned
+// together with a *VectorStoreFileBatchError.
+func (v *VectorStoresAPI) PollVectorStoreFileBatch(ctx context.Context, vectorStoreID, batchID string, interval time.Duration) (*VectorStoreFileBatch, error) {
+	const maxDelay = 10 * time.Second
+	delay := interval
+	if delay <= 0 {
+		delay = 500 * time.Millisecond
+	}
+	for attempt := 0; ; {
+		batch, _, err := v.RetrieveVectorStoreFileBatch(ctx, vectorStoreID, batchID)
+		wait := delay
+		switch {
+		case err != nil && !isRetryable(err):
+			return nil, err
+		case err != nil:
+			wait = retryDelay(attempt, err)
+			attempt++
+		case batch.Status == VectorStoreFileStatusInProgress:
+			if delay *= 2; delay > maxDelay {
+				delay = maxDelay
+			}
+		case batch.Status == VectorStoreFileStatusCompleted && batch.FileCounts.Failed == 0 && batch.FileCounts.Cancelled == 0:
+			return batch, nil
+		default:
+			return batch, &VectorStoreFileBatchError{ID: batch.ID, Status: batch.Status, FileCounts: batch.FileCounts}
+		}
+
+		if err := sleepCtx(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
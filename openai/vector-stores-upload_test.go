@@ -0,0 +1,132 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// uploadServer fakes the endpoints UploadAndPoll calls and records every
+// deletion and cancellation. createStatus and retrieveStatus are the statuses
+// of creating and retrieving the batch; retrieved is the batch returned on success.
+type uploadServer struct {
+	createStatus   int
+	retrieveStatus int
+	retrieved      string
+
+	mu        sync.Mutex
+	deleted   []string
+	cancelled bool
+}
+
+func (s *uploadServer) client(t *testing.T) *OpenAIClient {
+	t.Helper()
+	var uploads int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/files", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":"file-%d","purpose":"assistants"}`, atomic.AddInt32(&uploads, 1))
+	})
+	mux.HandleFunc("DELETE /v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.deleted = append(s.deleted, r.PathValue("id"))
+		s.mu.Unlock()
+		fmt.Fprintf(w, `{"id":%q,"deleted":true}`, r.PathValue("id"))
+	})
+	mux.HandleFunc("DELETE /v1/vector_stores/vs_1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.deleted = append(s.deleted, "vs_1/"+r.PathValue("id"))
+		s.mu.Unlock()
+		fmt.Fprintf(w, `{"id":%q,"deleted":true}`, r.PathValue("id"))
+	})
+	mux.HandleFunc("POST /v1/vector_stores/vs_1/file_batches", func(w http.ResponseWriter, r *http.Request) {
+		if s.createStatus != http.StatusOK {
+			writeAPIError(w, s.createStatus, "failed")
+			return
+		}
+		fmt.Fprint(w, `{"id":"vsfb_1","status":"in_progress"}`)
+	})
+	mux.HandleFunc("GET /v1/vector_stores/vs_1/file_batches/vsfb_1", func(w http.ResponseWriter, r *http.Request) {
+		if s.retrieveStatus != http.StatusOK {
+			writeAPIError(w, s.retrieveStatus, "failed")
+			return
+		}
+		fmt.Fprint(w, s.retrieved)
+	})
+	mux.HandleFunc("POST /v1/vector_stores/vs_1/file_batches/vsfb_1/cancel", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.cancelled = true
+		s.mu.Unlock()
+		fmt.Fprint(w, `{"id":"vsfb_1","status":"cancelling"}`)
+	})
+	return newTestClient(t, mux)
+}
+
+func uploadPaths(t *testing.T, n int) []string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := make([]string, n)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("doc%d.txt", i))
+		if err := os.WriteFile(paths[i], []byte("some text"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func TestUploadAndPollCleansUp(t *testing.T) {
+	const completedWithFailures = `{"id":"vsfb_1","status":"completed","file_counts":{"completed":1,"failed":1,"total":2}}`
+	tests := []struct {
+		name          string
+		srv           *uploadServer
+		wantDeleted   string
+		wantCancelled bool
+	}{
+		{
+			name:        "batch creation fails",
+			srv:         &uploadServer{createStatus: http.StatusBadRequest},
+			wantDeleted: "file-1 file-2",
+		},
+		{
+			name:          "polling fails",
+			srv:           &uploadServer{createStatus: http.StatusOK, retrieveStatus: http.StatusNotFound},
+			wantDeleted:   "file-1 file-2 vs_1/file-1 vs_1/file-2",
+			wantCancelled: true,
+		},
+		{
+			name: "some files fail indexing",
+			srv:  &uploadServer{createStatus: http.StatusOK, retrieveStatus: http.StatusOK, retrieved: completedWithFailures},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.srv.client(t)
+			_, err := c.VectorStores.UploadAndPoll(context.Background(), "vs_1", uploadPaths(t, 2), nil)
+			if err == nil {
+				t.Fatal("UploadAndPoll succeeded")
+			}
+			var batchErr *VectorStoreFileBatchError
+			if keep := tt.wantDeleted == ""; keep != errors.As(err, &batchErr) {
+				t.Errorf("err = %v", err)
+			}
+
+			sort.Strings(tt.srv.deleted)
+			if got := strings.Join(tt.srv.deleted, " "); got != tt.wantDeleted {
+				t.Errorf("deleted %q, want %q", got, tt.wantDeleted)
+			}
+			if tt.srv.cancelled != tt.wantCancelled {
+				t.Errorf("batch cancelled = %v, want %v", tt.srv.cancelled, tt.wantCancelled)
+			}
+		})
+	}
+}
@@ -0,0 +1,459 @@
+// Copyright 2023 The openai-go AUTHORS. All rights reserved.
+
+package openai
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type VectorStoresAPI Api
+
+// Statuses of a VectorStore.
+const (
+	VectorStoreStatusExpired    = "expired"
+	VectorStoreStatusInProgress = "in_progress"
+	VectorStoreStatusCompleted  = "completed"
+)
+
+// Statuses of a VectorStoreFile or a VectorStoreFileBatch.
+const (
+	VectorStoreFileStatusInProgress = "in_progress"
+	VectorStoreFileStatusCompleted  = "completed"
+	VectorStoreFileStatusCancelled  = "cancelled"
+	VectorStoreFileStatusFailed     = "failed"
+)
+
+// Types of ChunkingStrategy.
+const (
+	ChunkingStrategyAuto   = "auto"
+	ChunkingStrategyStatic = "static"
+)
+
+// ChunkingStrategy is how files are split before being embedded. Build one
+// with AutoChunking or StaticChunking.
+type ChunkingStrategy struct {
+	Type   string                  `json:"type"`
+	Static *StaticChunkingStrategy `json:"static,omitempty"`
+}
+
+// StaticChunkingStrategy splits files in chunks of MaxChunkSizeTokens, between
+// 100 and 4096, overlapping by ChunkOverlapTokens, at most half the size.
+type StaticChunkingStrategy struct {
+	MaxChunkSizeTokens int `json:"max_chunk_size_tokens"`
+	ChunkOverlapTokens int `json:"chunk_overlap_tokens"`
+}
+
+// AutoChunking lets the API choose, currently 800 token chunks overlapping by 400.
+func AutoChunking() *ChunkingStrategy {
+	return &ChunkingStrategy{Type: ChunkingStrategyAuto}
+}
+
+// StaticChunking splits files in chunks of maxTokens overlapping by overlapTokens.
+func StaticChunking(maxTokens, overlapTokens int) *ChunkingStrategy {
+	return &ChunkingStrategy{
+		Type:   ChunkingStrategyStatic,
+		Static: &StaticChunkingStrategy{MaxChunkSizeTokens: maxTokens, ChunkOverlapTokens: overlapTokens},
+	}
+}
+
+// ExpiresAfterLastActive is the only anchor of an ExpirationPolicy.
+const ExpiresAfterLastActive = "last_active_at"
+
+// ExpirationPolicy expires a vector store Days after its anchor.
+type ExpirationPolicy struct {
+	Anchor string `json:"anchor"`
+	Days   int    `json:"days"`
+}
+
+// ExpireAfterInactiveDays expires a vector store once unused for days.
+func ExpireAfterInactiveDays(days int) *ExpirationPolicy {
+	return &ExpirationPolicy{Anchor: ExpiresAfterLastActive, Days: days}
+}
+
+// VectorStoreRequest creates or modifies a vector store. FileIDs and
+// ChunkingStrategy are only used on creation.
+type VectorStoreRequest struct {
+	Name             string            `json:"name,omitempty"`
+	FileIDs          []string          `json:"file_ids,omitempty"`
+	ChunkingStrategy *ChunkingStrategy `json:"chunking_strategy,omitempty"`
+	ExpiresAfter     *ExpirationPolicy `json:"expires_after,omitempty"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+}
+
+type VectorStore struct {
+	ID           string                `json:"id"`
+	Object       string                `json:"object"`
+	CreatedAt    int64                 `json:"created_at"`
+	Name         string                `json:"name"`
+	UsageBytes   int64                 `json:"usage_bytes"`
+	FileCounts   VectorStoreFileCounts `json:"file_counts"`
+	Status       string                `json:"status"`
+	ExpiresAfter *ExpirationPolicy     `json:"expires_after"`
+	ExpiresAt    int64                 `json:"expires_at"`
+	LastActiveAt int64                 `json:"last_active_at"`
+	Metadata     map[string]string     `json:"metadata"`
+}
+
+type VectorStoreFileCounts struct {
+	InProgress int `json:"in_progress"`
+	Completed  int `json:"completed"`
+	Failed     int `json:"failed"`
+	Cancelled  int `json:"cancelled"`
+	Total      int `json:"total"`
+}
+
+type VectorStoreList struct {
+	Object  string        `json:"object"`
+	Data    []VectorStore `json:"data"`
+	FirstID string        `json:"first_id"`
+	LastID  string        `json:"last_id"`
+	HasMore bool          `json:"has_more"`
+}
+
+type VectorStoreDeleteResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
+
+// VectorStoreFileRequest attaches an uploaded file to a vector store.
+type VectorStoreFileRequest struct {
+	FileID           string            `json:"file_id"`
+	ChunkingStrategy *ChunkingStrategy `json:"chunking_strategy,omitempty"`
+}
+
+// VectorStoreFile is a file attached to a vector store. Its ID is the ID of
+// the file.
+type VectorStoreFile struct {
+	ID               string                `json:"id"`
+	Object           string                `json:"object"`
+	CreatedAt        int64                 `json:"created_at"`
+	VectorStoreID    string                `json:"vector_store_id"`
+	UsageBytes       int64                 `json:"usage_bytes"`
+	Status           string                `json:"status"`
+	LastError        *VectorStoreFileError `json:"last_error"`
+	ChunkingStrategy *ChunkingStrategy     `json:"chunking_strategy"`
+}
+
+// VectorStoreFileError is why a file failed to be indexed.
+type VectorStoreFileError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *VectorStoreFileError) Error() string {
+	return fmt.Sprintf("openai: vector store file %s: %s", e.Code, e.Message)
+}
+
+type VectorStoreFileList struct {
+	Object  string            `json:"object"`
+	Data    []VectorStoreFile `json:"data"`
+	FirstID string            `json:"first_id"`
+	LastID  string            `json:"last_id"`
+	HasMore bool              `json:"has_more"`
+}
+
+type VectorStoreFileDeleteResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
+
+// VectorStoreFileListOptions page through the files of a vector store or a
+// file batch, optionally only those with the status Filter.
+type VectorStoreFileListOptions struct {
+	ListOptions
+	Filter string
+}
+
+func (o *VectorStoreFileListOptions) encode(u string) string {
+	if o == nil {
+		return u
+	}
+	u = o.ListOptions.encode(u)
+	if o.Filter == "" {
+		return u
+	}
+	sep := "?"
+	if strings.Contains(u, "?") {
+		sep = "&"
+	}
+	return u + sep + "filter=" + url.QueryEscape(o.Filter)
+}
+
+// VectorStoreFileBatchRequest attaches several uploaded files to a vector store at once.
+type VectorStoreFileBatchRequest struct {
+	FileIDs          []string          `json:"file_ids"`
+	ChunkingStrategy *ChunkingStrategy `json:"chunking_strategy,omitempty"`
+}
+
+type VectorStoreFileBatch struct {
+	ID            string                `json:"id"`
+	Object        string                `json:"object"`
+	CreatedAt     int64                 `json:"created_at"`
+	VectorStoreID string                `json:"vector_store_id"`
+	Status        string                `json:"status"`
+	FileCounts    VectorStoreFileCounts `json:"file_counts"`
+}
+
+// CreateVectorStore creates a vector store, optionally with files to index.
+func (v *VectorStoresAPI) CreateVectorStore(ctx context.Context, vsReq *VectorStoreRequest) (*VectorStore, *Response, error) {
+	u := "v1/vector_stores"
+	req, err := v.openAIClient.NewRequest(http.MethodPost, u, vsReq, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := new(VectorStore)
+
+	resp, err := v.openAIClient.Do(ctx, req, store)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return store, resp, nil
+}
+
+// RetrieveVectorStore returns a vector store.
+func (v *VectorStoresAPI) RetrieveVectorStore(ctx context.Context, id string) (*VectorStore, *Response, error) {
+	u := fmt.Sprintf("v1/vector_stores/%s", id)
+	req, err := v.openAIClient.NewRequest(http.MethodGet, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := new(VectorStore)
+
+	resp, err := v.openAIClient.Do(ctx, req, store)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return store, resp, nil
+}
+
+// ModifyVectorStore changes the name, expiration policy and metadata of a vector store.
+func (v *VectorStoresAPI) ModifyVectorStore(ctx context.Context, id string, vsReq *VectorStoreRequest) (*VectorStore, *Response, error) {
+	u := fmt.Sprintf("v1/vector_stores/%s", id)
+	req, err := v.openAIClient.NewRequest(http.MethodPost, u, vsReq, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := new(VectorStore)
+
+	resp, err := v.openAIClient.Do(ctx, req, store)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return store, resp, nil
+}
+
+// DeleteVectorStore deletes a vector store. Its files are not deleted.
+func (v *VectorStoresAPI) DeleteVectorStore(ctx context.Context, id string) (*VectorStoreDeleteResponse, *Response, error) {
+	u := fmt.Sprintf("v1/vector_stores/%s", id)
+	req, err := v.openAIClient.NewRequest(http.MethodDelete, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vdResp := new(VectorStoreDeleteResponse)
+
+	resp, err := v.openAIClient.Do(ctx, req, vdResp)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return vdResp, resp, nil
+}
+
+// List returns your vector stores.
+func (v *VectorStoresAPI) List(ctx context.Context, opts *ListOptions) (*VectorStoreList, *Response, error) {
+	u := opts.encode("v1/vector_stores")
+	req, err := v.openAIClient.NewRequest(http.MethodGet, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list := new(VectorStoreList)
+
+	resp, err := v.openAIClient.Do(ctx, req, list)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return list, resp, nil
+}
+
+// ListAll iterates over all of your vector stores, fetching pages as needed.
+func (v *VectorStoresAPI) ListAll(ctx context.Context, opts *ListOptions) iter.Seq2[VectorStore, error] {
+	return paginate(ctx, opts, func(ctx context.Context, o *ListOptions) (page[VectorStore], error) {
+		l, _, err := v.List(ctx, o)
+		if err != nil {
+			return page[VectorStore]{}, err
+		}
+		return page[VectorStore]{items: l.Data, hasMore: l.HasMore, lastID: l.LastID}, nil
+	}, func(store VectorStore) string { return store.ID })
+}
+
+// CreateVectorStoreFile attaches an uploaded file to a vector store, which
+// starts indexing it.
+func (v *VectorStoresAPI) CreateVectorStoreFile(ctx context.Context, vectorStoreID string, vfReq *VectorStoreFileRequest) (*VectorStoreFile, *Response, error) {
+	u := fmt.Sprintf("v1/vector_stores/%s/files", vectorStoreID)
+	req, err := v.openAIClient.NewRequest(http.MethodPost, u, vfReq, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file := new(VectorStoreFile)
+
+	resp, err := v.openAIClient.Do(ctx, req, file)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return file, resp, nil
+}
+
+// RetrieveVectorStoreFile returns a file of a vector store.
+func (v *VectorStoresAPI) RetrieveVectorStoreFile(ctx context.Context, vectorStoreID, fileID string) (*VectorStoreFile, *Response, error) {
+	u := fmt.Sprintf("v1/vector_stores/%s/files/%s", vectorStoreID, fileID)
+	req, err := v.openAIClient.NewRequest(http.MethodGet, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file := new(VectorStoreFile)
+
+	resp, err := v.openAIClient.Do(ctx, req, file)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return file, resp, nil
+}
+
+// DeleteVectorStoreFile removes a file from a vector store. The file itself
+// is not deleted; use FileAPI.DeleteFile for that.
+func (v *VectorStoresAPI) DeleteVectorStoreFile(ctx context.Context, vectorStoreID, fileID string) (*VectorStoreFileDeleteResponse, *Response, error) {
+	u := fmt.Sprintf("v1/vector_stores/%s/files/%s", vectorStoreID, fileID)
+	req, err := v.openAIClient.NewRequest(http.MethodDelete, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fdResp := new(VectorStoreFileDeleteResponse)
+
+	resp, err := v.openAIClient.Do(ctx, req, fdResp)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return fdResp, resp, nil
+}
+
+// ListVectorStoreFiles returns the files of a vector store.
+func (v *VectorStoresAPI) ListVectorStoreFiles(ctx context.Context, vectorStoreID string, opts *VectorStoreFileListOptions) (*VectorStoreFileList, *Response, error) {
+	u := opts.encode(fmt.Sprintf("v1/vector_stores/%s/files", vectorStoreID))
+	return v.listFiles(ctx, u)
+}
+
+// ListAllVectorStoreFiles iterates over all files of a vector store, fetching
+// pages as needed.
+func (v *VectorStoresAPI) ListAllVectorStoreFiles(ctx context.Context, vectorStoreID string, opts *VectorStoreFileListOptions) iter.Seq2[VectorStoreFile, error] {
+	return v.listAllFiles(ctx, opts, func(ctx context.Context, o *VectorStoreFileListOptions) (*VectorStoreFileList, *Response, error) {
+		return v.ListVectorStoreFiles(ctx, vectorStoreID, o)
+	})
+}
+
+// CreateVectorStoreFileBatch attaches several uploaded files to a vector
+// store, which starts indexing them.
+func (v *VectorStoresAPI) CreateVectorStoreFileBatch(ctx context.Context, vectorStoreID string, fbReq *VectorStoreFileBatchRequest) (*VectorStoreFileBatch, *Response, error) {
+	u := fmt.Sprintf("v1/vector_stores/%s/file_batches", vectorStoreID)
+	return v.doFileBatch(ctx, http.MethodPost, u, fbReq)
+}
+
+// RetrieveVectorStoreFileBatch returns a file batch of a vector store.
+func (v *VectorStoresAPI) RetrieveVectorStoreFileBatch(ctx context.Context, vectorStoreID, batchID string) (*VectorStoreFileBatch, *Response, error) {
+	u := fmt.Sprintf("v1/vector_stores/%s/file_batches/%s", vectorStoreID, batchID)
+	return v.doFileBatch(ctx, http.MethodGet, u, nil)
+}
+
+// CancelVectorStoreFileBatch stops indexing the files of a batch that are not
+// done yet.
+func (v *VectorStoresAPI) CancelVectorStoreFileBatch(ctx context.Context, vectorStoreID, batchID string) (*VectorStoreFileBatch, *Response, error) {
+	u := fmt.Sprintf("v1/vector_stores/%s/file_batches/%s/cancel", vectorStoreID, batchID)
+	return v.doFileBatch(ctx, http.MethodPost, u, nil)
+}
+
+// ListVectorStoreFileBatchFiles returns the files of a file batch.
+func (v *VectorStoresAPI) ListVectorStoreFileBatchFiles(ctx context.Context, vectorStoreID, batchID string, opts *VectorStoreFileListOptions) (*VectorStoreFileList, *Response, error) {
+	u := opts.encode(fmt.Sprintf("v1/vector_stores/%s/file_batches/%s/files", vectorStoreID, batchID))
+	return v.listFiles(ctx, u)
+}
+
+// ListAllVectorStoreFileBatchFiles iterates over all files of a file batch,
+// fetching pages as needed.
+func (v *VectorStoresAPI) ListAllVectorStoreFileBatchFiles(ctx context.Context, vectorStoreID, batchID string, opts *VectorStoreFileListOptions) iter.Seq2[VectorStoreFile, error] {
+	return v.listAllFiles(ctx, opts, func(ctx context.Context, o *VectorStoreFileListOptions) (*VectorStoreFileList, *Response, error) {
+		return v.ListVectorStoreFileBatchFiles(ctx, vectorStoreID, batchID, o)
+	})
+}
+
+// doFileBatch sends a file batch request and decodes the batch it returns.
+func (v *VectorStoresAPI) doFileBatch(ctx context.Context, method, u string, body interface{}) (*VectorStoreFileBatch, *Response, error) {
+	req, err := v.openAIClient.NewRequest(method, u, body, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	batch := new(VectorStoreFileBatch)
+
+	resp, err := v.openAIClient.Do(ctx, req, batch)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return batch, resp, nil
+}
+
+// listFiles fetches a page of vector store files from u.
+func (v *VectorStoresAPI) listFiles(ctx context.Context, u string) (*VectorStoreFileList, *Response, error) {
+	req, err := v.openAIClient.NewRequest(http.MethodGet, u, nil, assistantsBeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list := new(VectorStoreFileList)
+
+	resp, err := v.openAIClient.Do(ctx, req, list)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return list, resp, nil
+}
+
+// listAllFiles pages through the vector store files returned by list.
+func (v *VectorStoresAPI) listAllFiles(ctx context.Context, opts *VectorStoreFileListOptions, list func(context.Context, *VectorStoreFileListOptions) (*VectorStoreFileList, *Response, error)) iter.Seq2[VectorStoreFile, error] {
+	var (
+		start  ListOptions
+		filter string
+	)
+	if opts != nil {
+		start, filter = opts.ListOptions, opts.Filter
+	}
+	return paginate(ctx, &start, func(ctx context.Context, o *ListOptions) (page[VectorStoreFile], error) {
+		l, _, err := list(ctx, &VectorStoreFileListOptions{ListOptions: *o, Filter: filter})
+		if err != nil {
+			return page[VectorStoreFile]{}, err
+		}
+		return page[VectorStoreFile]{items: l.Data, hasMore: l.HasMore, lastID: l.LastID}, nil
+	}, func(file VectorStoreFile) string { return file.ID })
+}